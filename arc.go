@@ -0,0 +1,135 @@
+// Copyright 2016 zxfonline@sina.com. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package lru
+
+// ARCCache is a cache implementing the Adaptive Replacement Cache
+// algorithm. Entries seen once are kept in a recency list (T1) and
+// entries seen again in a frequency list (T2); the keys evicted from
+// each are remembered without their value in a ghost list (B1 and B2).
+// A key added again while in a ghost list moves the target size of T1
+// toward the list it was evicted from, so the cache tunes itself between
+// recency and frequency without parameters.
+// The ghost lists hold up to maxEntries keys each, so the cache keeps
+// track of up to twice as many keys as it holds values.
+// It is not safe for concurrent access.
+type ARCCache struct {
+	size int
+	p    int // target size of t1
+
+	t1 *Cache // recent entries
+	t2 *Cache // frequent entries
+	b1 *Cache // keys evicted from t1
+	b2 *Cache // keys evicted from t2
+}
+
+// NewARC creates a new ARCCache holding up to maxEntries entries.
+// It panics if maxEntries is not positive.
+func NewARC(maxEntries int) *ARCCache {
+	if maxEntries <= 0 {
+		panic("lru: ARCCache size must be positive")
+	}
+	return &ARCCache{
+		size: maxEntries,
+		t1:   New(0),
+		t2:   New(0),
+		b1:   New(maxEntries),
+		b2:   New(maxEntries),
+	}
+}
+
+// Add adds a value to the cache.
+func (c *ARCCache) Add(key Key, value interface{}) {
+	if c.t1.Contains(key) {
+		c.t1.Remove(key)
+		c.t2.Add(key, value)
+		return
+	}
+	if c.t2.Contains(key) {
+		c.t2.Add(key, value)
+		return
+	}
+	if c.b1.Contains(key) {
+		c.p += arcDelta(c.b2.Len(), c.b1.Len())
+		if c.p > c.size {
+			c.p = c.size
+		}
+		if c.Len() >= c.size {
+			c.replace(false)
+		}
+		c.b1.Remove(key)
+		c.t2.Add(key, value)
+		return
+	}
+	if c.b2.Contains(key) {
+		c.p -= arcDelta(c.b1.Len(), c.b2.Len())
+		if c.p < 0 {
+			c.p = 0
+		}
+		if c.Len() >= c.size {
+			c.replace(true)
+		}
+		c.b2.Remove(key)
+		c.t2.Add(key, value)
+		return
+	}
+	if c.Len() >= c.size {
+		c.replace(false)
+	}
+	if c.b1.Len() > c.size-c.p {
+		c.b1.RemoveOldest()
+	}
+	if c.b2.Len() > c.p {
+		c.b2.RemoveOldest()
+	}
+	c.t1.Add(key, value)
+}
+
+// arcDelta returns how much a hit in a ghost list of length n moves the
+// target size when the other ghost list has length other.
+func arcDelta(other, n int) int {
+	if other > n {
+		return other / n
+	}
+	return 1
+}
+
+// replace evicts the oldest entry of t1 or t2, depending on the target
+// size, into its ghost list. inB2 tells whether the key being added was
+// found in b2.
+func (c *ARCCache) replace(inB2 bool) {
+	if n := c.t1.Len(); n > 0 && (n > c.p || n == c.p && inB2) {
+		if key, _, ok := c.t1.PopOldest(); ok {
+			c.b1.Add(key, nil)
+		}
+		return
+	}
+	if key, _, ok := c.t2.PopOldest(); ok {
+		c.b2.Add(key, nil)
+	}
+}
+
+// Get looks up a key's value from the cache. A hit in the recency list
+// moves the entry to the frequency list.
+func (c *ARCCache) Get(key Key) (value interface{}, ok bool) {
+	if value, ok = c.t1.Peek(key); ok {
+		c.t1.Remove(key)
+		c.t2.Add(key, value)
+		return value, true
+	}
+	return c.t2.Get(key)
+}
+
+// Remove removes the provided key from the cache, ghost lists included.
+func (c *ARCCache) Remove(key Key) {
+	c.t1.Remove(key)
+	c.t2.Remove(key)
+	c.b1.Remove(key)
+	c.b2.Remove(key)
+}
+
+// Len returns the number of items in the cache.
+func (c *ARCCache) Len() int {
+	return c.t1.Len() + c.t2.Len()
+}
@@ -0,0 +1,44 @@
+// Copyright 2016 zxfonline@sina.com. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package lru
+
+import "testing"
+
+func TestARCAdaptsToGhostHits(t *testing.T) {
+	c := NewARC(2)
+	c.Add("a", 1)
+	c.Add("b", 2)
+	c.Add("c", 3) // evicts a from T1 into B1
+	if !c.b1.Contains("a") || c.p != 0 {
+		t.Fatalf("after c: a in B1 = %v, p = %d; want true, 0", c.b1.Contains("a"), c.p)
+	}
+
+	// A hit in B1 grows the target size of T1 and moves a to T2.
+	c.Add("a", 1)
+	if c.p != 1 {
+		t.Fatalf("p = %d after a B1 hit, want 1", c.p)
+	}
+	if !c.t2.Contains("a") || !c.b1.Contains("b") {
+		t.Fatalf("a in T2 = %v, b in B1 = %v; want true, true", c.t2.Contains("a"), c.b1.Contains("b"))
+	}
+
+	// T1 is at its target size, so d evicts a from T2 into B2.
+	c.Add("d", 4)
+	if !c.b2.Contains("a") {
+		t.Fatal("a not in B2")
+	}
+
+	// A hit in B2 shrinks the target size of T1 again.
+	c.Add("a", 1)
+	if c.p != 0 {
+		t.Fatalf("p = %d after a B2 hit, want 0", c.p)
+	}
+	if v, ok := c.Get("a"); !ok || v != 1 {
+		t.Fatalf("Get(a) = %v, %v; want 1, true", v, ok)
+	}
+	if c.Len() != 2 {
+		t.Fatalf("Len() = %d, want 2", c.Len())
+	}
+}
@@ -0,0 +1,56 @@
+// Copyright 2016 zxfonline@sina.com. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package lru
+
+// Clone returns an independent copy of the cache with the same items in
+// the same order, limits, TTL, cost function and eviction policy.
+// Callbacks and eviction channels are not copied and no callback is
+// called. Values are copied as is, see CloneFunc for deep copies.
+func (c *Cache) Clone() *Cache {
+	return c.CloneFunc(nil)
+}
+
+// CloneFunc is like Clone but stores copyValue(v) for every value v,
+// unless copyValue is nil.
+func (c *Cache) CloneFunc(copyValue func(interface{}) interface{}) *Cache {
+	n := NewWithOptions(
+		WithMaxEntries(c.MaxEntries),
+		WithNoEvict(c.NoEvict),
+		WithEvictBatch(c.EvictBatch),
+		WithDefaultTTL(c.DefaultTTL),
+		WithTTLJitter(c.TTLJitter),
+		WithSlidingTTL(c.SlidingTTL),
+		WithMaxAge(c.MaxAge),
+		WithMaxBytes(c.MaxBytes),
+		WithCost(c.Cost),
+		WithDefaultEntrySize(c.DefaultEntrySize),
+		WithKeyFunc(c.KeyFunc),
+	)
+	n.trackTime = c.trackTime
+	n.insertionOrder = c.insertionOrder
+	n.pooled = c.pooled
+	if c.policy != nil {
+		n.policy = c.policy.clone()
+	}
+	if c.admit != nil {
+		n.admit = c.admit.clone()
+	}
+	if c.Cache == nil {
+		return n
+	}
+	for ele := c.Ll.Back(); ele != nil; ele = ele.Prev() {
+		kv := *ele.Value.(*entry)
+		kv.refs = append([]uint64(nil), kv.refs...)
+		if copyValue != nil {
+			kv.value = copyValue(kv.value)
+		}
+		ele := n.Ll.PushFront(&kv)
+		n.Cache[kv.key] = ele
+		n.schedule(ele)
+	}
+	n.bytes = c.bytes
+	n.prioritized = c.prioritized
+	return n
+}
@@ -0,0 +1,35 @@
+// Copyright 2016 zxfonline@sina.com. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package lru
+
+import "context"
+
+// ForeachContext walks the cache from the oldest item like Foreach.
+// It stops and returns ctx.Err() if ctx is done before an item, or the
+// first non-nil error returned by fn.
+func (c *Cache) ForeachContext(ctx context.Context, fn func(Key, interface{}) error) error {
+	if c.Cache == nil {
+		return ctx.Err()
+	}
+	for ele := c.Ll.Back(); ele != nil; ele = ele.Prev() {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		kv := ele.Value.(*entry)
+		if err := fn(kv.key, kv.value); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ForeachContext walks the cache from the oldest item until ctx is done
+// or fn fails, see Cache.ForeachContext.
+// fn runs with the cache lock held, so it must not call back into c.
+func (c *SyncCache) ForeachContext(ctx context.Context, fn func(Key, interface{}) error) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.c.ForeachContext(ctx, fn)
+}
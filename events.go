@@ -0,0 +1,59 @@
+// Copyright 2016 zxfonline@sina.com. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package lru
+
+// EvictEvent describes an entry that left the cache.
+type EvictEvent struct {
+	Key    Key
+	Value  interface{}
+	Reason EvictReason
+}
+
+// EvictionChannel returns a channel receiving an EvictEvent for every entry
+// that leaves the cache, for any reason. The cache never blocks on it: when
+// the buffer of the channel is full, events are dropped.
+// Call StopEvictionChannel to unsubscribe.
+func (c *Cache) EvictionChannel(buffer int) <-chan EvictEvent {
+	ch := make(chan EvictEvent, buffer)
+	c.subs = append(c.subs, ch)
+	return ch
+}
+
+// StopEvictionChannel unsubscribes and closes a channel returned by
+// EvictionChannel. It does nothing if ch is not subscribed.
+func (c *Cache) StopEvictionChannel(ch <-chan EvictEvent) {
+	for i, sub := range c.subs {
+		if sub == ch {
+			c.subs = append(c.subs[:i], c.subs[i+1:]...)
+			close(sub)
+			return
+		}
+	}
+}
+
+func (c *Cache) publish(ev EvictEvent) {
+	for _, sub := range c.subs {
+		select {
+		case sub <- ev:
+		default:
+		}
+	}
+}
+
+// EvictionChannel returns a channel receiving the eviction events of the
+// cache, see Cache.EvictionChannel.
+func (c *SyncCache) EvictionChannel(buffer int) <-chan EvictEvent {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.c.EvictionChannel(buffer)
+}
+
+// StopEvictionChannel unsubscribes and closes a channel returned by
+// EvictionChannel.
+func (c *SyncCache) StopEvictionChannel(ch <-chan EvictEvent) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.c.StopEvictionChannel(ch)
+}
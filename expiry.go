@@ -0,0 +1,119 @@
+// Copyright 2016 zxfonline@sina.com. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package lru
+
+import (
+	"container/heap"
+	"container/list"
+	"time"
+)
+
+// deadline is a scheduled expiry of an element. It keeps the key of the
+// element, because a pooled entry may belong to another cache once its
+// element has left this one.
+type deadline struct {
+	at  time.Time
+	key Key
+	ele *list.Element
+}
+
+// deadlines is a min-heap of the expiries of the entries with a TTL or
+// a MaxAge. Items are never removed when an entry changes or leaves the
+// cache; they are checked against the entry when they reach the top.
+type deadlines []deadline
+
+func (h deadlines) Len() int            { return len(h) }
+func (h deadlines) Less(i, j int) bool  { return h[i].at.Before(h[j].at) }
+func (h deadlines) Swap(i, j int)       { h[i], h[j] = h[j], h[i] }
+func (h *deadlines) Push(x interface{}) { *h = append(*h, x.(deadline)) }
+func (h *deadlines) Pop() interface{} {
+	old := *h
+	d := old[len(old)-1]
+	*h = old[:len(old)-1]
+	return d
+}
+
+// deadline returns when e expires, by TTL or MaxAge, or the zero time
+// if it never does.
+func (c *Cache) deadline(e *entry) time.Time {
+	at := e.expire
+	if c.MaxAge > 0 && !e.added.IsZero() {
+		if aged := e.added.Add(c.MaxAge); at.IsZero() || aged.Before(at) {
+			at = aged
+		}
+	}
+	return at
+}
+
+// schedule records the current expiry of ele, if any. It must be called
+// whenever the expiry of an entry changes.
+func (c *Cache) schedule(ele *list.Element) {
+	at := c.deadline(ele.Value.(*entry))
+	if at.IsZero() {
+		return
+	}
+	if len(c.deadlines) > 2*len(c.Cache)+64 {
+		c.compactDeadlines()
+	}
+	heap.Push(&c.deadlines, deadline{at, ele.Value.(*entry).key, ele})
+}
+
+// current reports whether d is the latest scheduled expiry of an entry
+// still in the cache.
+func (c *Cache) current(d deadline) bool {
+	if ele, ok := c.Cache[d.key]; !ok || ele != d.ele {
+		return false
+	}
+	return c.deadline(d.ele.Value.(*entry)).Equal(d.at)
+}
+
+// compactDeadlines drops the items of the heap that are out of date.
+func (c *Cache) compactDeadlines() {
+	live := c.deadlines[:0]
+	for _, d := range c.deadlines {
+		if c.current(d) {
+			live = append(live, d)
+		}
+	}
+	for i := len(live); i < len(c.deadlines); i++ {
+		c.deadlines[i] = deadline{}
+	}
+	c.deadlines = live
+	heap.Init(&c.deadlines)
+}
+
+// rebuildDeadlines schedules the expiry of every entry again, after
+// MaxAge changed.
+func (c *Cache) rebuildDeadlines() {
+	for i := range c.deadlines {
+		c.deadlines[i] = deadline{}
+	}
+	c.deadlines = c.deadlines[:0]
+	for ele := c.Ll.Front(); ele != nil; ele = ele.Next() {
+		kv := ele.Value.(*entry)
+		if at := c.deadline(kv); !at.IsZero() {
+			c.deadlines = append(c.deadlines, deadline{at, kv.key, ele})
+		}
+	}
+	heap.Init(&c.deadlines)
+	c.heapAge = c.MaxAge
+}
+
+// removeDue removes the entries whose expiry is before now, in the order
+// they expired, and returns their number.
+func (c *Cache) removeDue(now time.Time) int {
+	if c.MaxAge != c.heapAge {
+		c.rebuildDeadlines()
+	}
+	removed := 0
+	for len(c.deadlines) > 0 && c.deadlines[0].at.Before(now) {
+		d := heap.Pop(&c.deadlines).(deadline)
+		if c.current(d) {
+			c.removeElement(d.ele, ReasonExpired)
+			removed++
+		}
+	}
+	return removed
+}
@@ -0,0 +1,49 @@
+// Copyright 2016 zxfonline@sina.com. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package lru
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestRemoveDueInExpiryOrder(t *testing.T) {
+	var removed []Key
+	c := NewWithOptions(WithOnEvicted(func(key Key, value interface{}) {
+		removed = append(removed, key)
+	}))
+	c.AddWithTTL("a", 1, time.Hour)
+	c.AddWithTTL("b", 2, 2*time.Hour)
+	c.AddWithTTL("c", 3, 3*time.Hour)
+	c.Add("d", 4)                 // never expires
+	c.ExtendTTL("a", 3*time.Hour) // a now expires after c
+	c.ExtendTTL("d", 90*time.Minute)
+	c.Remove("b")
+	c.Compact()
+	if n := len(c.deadlines); n != 3 {
+		t.Fatalf("%d deadlines after Compact, want 3", n)
+	}
+
+	removed = nil
+	if n := c.removeDue(time.Now().Add(5 * time.Hour)); n != 3 {
+		t.Fatalf("removeDue() = %d, want 3", n)
+	}
+	if want := []Key{"d", "c", "a"}; !reflect.DeepEqual(removed, want) {
+		t.Fatalf("removed %v, want %v", removed, want)
+	}
+	if c.Len() != 0 || len(c.deadlines) != 0 {
+		t.Fatalf("Len() = %d, %d deadlines left; want 0, 0", c.Len(), len(c.deadlines))
+	}
+}
+
+func TestRemoveDueStopsAtNow(t *testing.T) {
+	c := New(0)
+	c.AddWithTTL("a", 1, time.Hour)
+	c.AddWithTTL("b", 2, 3*time.Hour)
+	if n := c.removeDue(time.Now().Add(2 * time.Hour)); n != 1 || !c.Contains("b") {
+		t.Fatalf("removeDue() = %d, Contains(b) = %v; want 1, true", n, c.Contains("b"))
+	}
+}
@@ -0,0 +1,49 @@
+// Copyright 2016 zxfonline@sina.com. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package lru
+
+import "expvar"
+
+// expvarStats is the JSON form of the published statistics.
+type expvarStats struct {
+	Len       int     `json:"len"`
+	Cap       int     `json:"cap"`
+	Hits      uint64  `json:"hits"`
+	Misses    uint64  `json:"misses"`
+	Evictions uint64  `json:"evictions"`
+	HitRatio  float64 `json:"hit_ratio"`
+}
+
+func newExpvarStats(s Stats, n, max int) expvarStats {
+	return expvarStats{
+		Len:       n,
+		Cap:       max,
+		Hits:      s.Hits,
+		Misses:    s.Misses,
+		Evictions: s.Evictions,
+		HitRatio:  s.HitRatio(),
+	}
+}
+
+// PublishExpvar publishes the statistics of the cache as an expvar.Var
+// named name, computed each time it is read, e.g. from /debug/vars.
+// Like expvar.Publish it panics if name is already in use.
+// Reading the var is not synchronized with the cache, use
+// SyncCache.PublishExpvar for a cache used from several goroutines.
+func (c *Cache) PublishExpvar(name string) {
+	expvar.Publish(name, expvar.Func(func() interface{} {
+		return newExpvarStats(c.Stats(), c.Len(), c.Cap())
+	}))
+}
+
+// PublishExpvar publishes the statistics of the cache as an expvar.Var
+// named name, read under the cache lock, see Cache.PublishExpvar.
+func (c *SyncCache) PublishExpvar(name string) {
+	expvar.Publish(name, expvar.Func(func() interface{} {
+		c.mu.Lock()
+		defer c.mu.Unlock()
+		return newExpvarStats(c.c.Stats(), c.c.Len(), c.c.Cap())
+	}))
+}
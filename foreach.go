@@ -0,0 +1,65 @@
+// Copyright 2016 zxfonline@sina.com. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package lru
+
+// Action tells ForeachMutable what to do with the current item.
+type Action int
+
+const (
+	Continue      Action = iota // keep the item and go on
+	Stop                        // keep the item and stop
+	Delete                      // remove the item and go on
+	DeleteAndStop               // remove the item and stop
+)
+
+// ForeachMutable walks the cache from the oldest to the newest item and
+// applies the Action returned by fn to each of them. Removed items are
+// passed to OnEvicted. The walk moves past an item before removing it,
+// so deleting the current item is always safe; fn itself must not
+// modify the cache.
+func (c *Cache) ForeachMutable(fn func(Key, interface{}) Action) {
+	if c.Cache == nil {
+		return
+	}
+	for ele := c.Ll.Back(); ele != nil; {
+		cur, kv := ele, ele.Value.(*entry)
+		ele = ele.Prev()
+		action := fn(kv.key, kv.value)
+		if action == Delete || action == DeleteAndStop {
+			c.removeElement(cur, ReasonDeleted)
+		}
+		if action == Stop || action == DeleteAndStop {
+			return
+		}
+	}
+}
+
+// ForeachMutable walks the cache from the oldest item, see
+// Cache.ForeachMutable.
+// fn runs with the cache lock held, so it must not call back into c.
+func (c *SyncCache) ForeachMutable(fn func(Key, interface{}) Action) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.c.ForeachMutable(fn)
+}
+
+// ForeachFilter walks the cache from the oldest item like Foreach but
+// only calls fn for the items for which match returns true. fn returns
+// true to stop the walk. Neither function may modify the cache.
+func (c *Cache) ForeachFilter(match func(Key, interface{}) bool, fn func(Key, interface{}) bool) {
+	c.Foreach(func(key Key, value interface{}) bool {
+		return match(key, value) && fn(key, value)
+	})
+}
+
+// ForeachFilter walks the matching items of the cache from the oldest,
+// see Cache.ForeachFilter.
+// match and fn run with the cache lock held, so they must not call back
+// into c.
+func (c *SyncCache) ForeachFilter(match func(Key, interface{}) bool, fn func(Key, interface{}) bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.c.ForeachFilter(match, fn)
+}
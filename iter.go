@@ -0,0 +1,25 @@
+// Copyright 2016 zxfonline@sina.com. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+//go:build go1.23
+
+package lru
+
+import "iter"
+
+// All returns an iterator over the items of the cache from the oldest
+// to the newest, for use with range:
+//
+//	for k, v := range c.All() {
+//		fmt.Println(k, v)
+//	}
+//
+// The cache must not be modified during the loop.
+func (c *Cache) All() iter.Seq2[Key, interface{}] {
+	return func(yield func(Key, interface{}) bool) {
+		c.Foreach(func(key Key, value interface{}) bool {
+			return !yield(key, value)
+		})
+	}
+}
@@ -0,0 +1,48 @@
+// Copyright 2016 zxfonline@sina.com. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package lru
+
+import "container/list"
+
+// Iterator walks a Cache from the oldest to the newest item.
+// Like with container/list, modifying the cache while iterating,
+// including with Get, invalidates the iterator.
+//
+//	for it := c.Iterator(); it.Next(); {
+//		fmt.Println(it.Key(), it.Value())
+//	}
+type Iterator struct {
+	cur, next *list.Element
+}
+
+// Iterator returns an iterator positioned before the oldest item.
+func (c *Cache) Iterator() *Iterator {
+	it := &Iterator{}
+	if c.Cache != nil {
+		it.next = c.Ll.Back()
+	}
+	return it
+}
+
+// Next advances the iterator to the next item and reports whether
+// there was one.
+func (it *Iterator) Next() bool {
+	it.cur = it.next
+	if it.cur == nil {
+		return false
+	}
+	it.next = it.cur.Prev()
+	return true
+}
+
+// Key returns the key of the current item.
+func (it *Iterator) Key() Key {
+	return it.cur.Value.(*entry).key
+}
+
+// Value returns the value of the current item.
+func (it *Iterator) Value() interface{} {
+	return it.cur.Value.(*entry).value
+}
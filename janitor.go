@@ -0,0 +1,69 @@
+// Copyright 2016 zxfonline@sina.com. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package lru
+
+import (
+	"sync"
+	"time"
+)
+
+// RemoveExpired removes every expired item from the cache, calling
+// OnEvicted for each of them. It returns the number of removed items.
+// The expiries are kept in a heap, so only the expired items are
+// visited, in O(k log n) for k of them, except after MaxAge changed,
+// when every item is visited once.
+func (c *Cache) RemoveExpired() int {
+	if c.Cache == nil {
+		return 0
+	}
+	return c.removeDue(time.Now())
+}
+
+// StartJanitor starts a goroutine calling RemoveExpired every interval
+// and returns a function that stops it. A zero or negative interval
+// starts nothing and returns a stop function that does nothing.
+// The sweep runs concurrently with the caller, so the cache must not be
+// used by anyone else while the janitor runs; use SyncCache.StartJanitor
+// for a cache that is shared.
+func (c *Cache) StartJanitor(interval time.Duration) (stop func()) {
+	return startJanitor(interval, func() { c.RemoveExpired() })
+}
+
+// RemoveExpired removes every expired item from the cache,
+// see Cache.RemoveExpired.
+func (c *SyncCache) RemoveExpired() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.c.RemoveExpired()
+}
+
+// StartJanitor starts a goroutine removing expired items every interval
+// under the cache lock and returns a function that stops it.
+// Each call starts an independent janitor; a zero or negative interval
+// starts none, see Cache.StartJanitor.
+func (c *SyncCache) StartJanitor(interval time.Duration) (stop func()) {
+	return startJanitor(interval, func() { c.RemoveExpired() })
+}
+
+func startJanitor(interval time.Duration, sweep func()) (stop func()) {
+	if interval <= 0 {
+		return func() {}
+	}
+	ticker := time.NewTicker(interval)
+	done := make(chan struct{})
+	go func() {
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C:
+				sweep()
+			case <-done:
+				return
+			}
+		}
+	}()
+	var once sync.Once
+	return func() { once.Do(func() { close(done) }) }
+}
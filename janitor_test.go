@@ -0,0 +1,17 @@
+// Copyright 2016 zxfonline@sina.com. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package lru
+
+import (
+	"testing"
+	"time"
+)
+
+func TestStartJanitorNonPositiveInterval(t *testing.T) {
+	for _, interval := range []time.Duration{0, -time.Second} {
+		New(0).StartJanitor(interval)()
+		NewSync(0).StartJanitor(interval)()
+	}
+}
@@ -0,0 +1,32 @@
+// Copyright 2016 zxfonline@sina.com. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package lru
+
+// GetOrLoadSync looks up a key's value from the cache and, on a miss,
+// calls Loader and caches the value it found. It returns false if the key
+// is neither cached nor found by Loader, or if Loader is nil.
+// With CacheNegative set, a key Loader did not find is remembered and
+// reported missing without calling Loader again until its tombstone goes.
+func (c *Cache) GetOrLoadSync(key Key) (interface{}, bool) {
+	if ele, hit := c.find(key); hit && ele.Value.(*entry).tombstone {
+		c.stats.Misses++
+		return nil, false
+	}
+	if value, ok := c.Get(key); ok {
+		return value, true
+	}
+	if c.Loader == nil {
+		return nil, false
+	}
+	value, ok := c.Loader(key)
+	if ok {
+		c.Add(key, value)
+		return value, true
+	}
+	if c.CacheNegative {
+		c.addTombstone(key, expireAt(c.NegativeTTL))
+	}
+	return nil, false
+}
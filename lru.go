@@ -5,67 +5,667 @@
 
 package lru
 
-import "container/list"
+import (
+	"container/list"
+	"strconv"
+	"time"
+)
 
-// Cache is an LRU cache. It is not safe for concurrent access.
+// Cache is an LRU cache. It is not safe for concurrent access,
+// use SyncCache for that.
 type Cache struct {
 	// MaxEntries is the maximum number of cache entries before
 	// an item is evicted. Zero means no limit.
 	MaxEntries int
 
+	// NoEvict makes the cache reject new keys instead of evicting items
+	// when it is full: Add then leaves the cache unchanged, AddChecked
+	// returns false, and updates of keys already in the cache still
+	// succeed, unless they would exceed MaxBytes. Expired items are
+	// removed to make room. Resize and RemoveOldest still evict.
+	NoEvict bool
+
+	// EvictBatch is the number of items evicted at once when an Add
+	// takes the cache over MaxEntries, so that eviction and OnEvicted
+	// run in batches rather than for every Add. The cache then holds
+	// between MaxEntries-EvictBatch+1 and MaxEntries items. Zero or one
+	// evicts a single item, keeping the cache at MaxEntries.
+	EvictBatch int
+
 	// OnEvicted optionally specificies a callback function to be
 	// executed when an entry is purged from the cache.
 	OnEvicted func(key Key, value interface{})
 
+	// OnEvictedReason is like OnEvicted but also receives why the entry
+	// left the cache. Both are called when both are set.
+	OnEvictedReason func(key Key, value interface{}, reason EvictReason)
+
+	// OnAdded optionally specifies a callback function to be
+	// executed when a new key is added to the cache.
+	OnAdded func(key Key, value interface{})
+
+	// OnUpdated optionally specifies a callback function to be
+	// executed when the value of a key already in the cache is replaced.
+	OnUpdated func(key Key, oldValue, newValue interface{})
+
+	// OnThrash optionally specifies a callback function to be
+	// executed when a key is added again after it was evicted to make
+	// room at least ThrashThreshold times recently, a sign that the
+	// cache is too small for its workload. count is an estimate of those
+	// evictions. Evicted keys are counted in a fixed-size sketch whose
+	// counts are halved over time, so no unbounded history is kept.
+	OnThrash        func(key Key, count int)
+	ThrashThreshold int
+
+	// OnDirtyEvicted optionally specifies a callback function to be
+	// executed, after OnEvicted, when an entry added with AddDirty and
+	// not flushed since leaves the cache for any reason but an explicit
+	// removal such as Remove, so that its value can still be written.
+	OnDirtyEvicted func(key Key, value interface{})
+
+	// HighWaterMark is a fraction of MaxEntries, such as 0.9. When it is
+	// set, OnHighWater is called once each time the number of items
+	// reaches that fraction, and OnLowWater once each time it falls
+	// back below it, with the number of items and MaxEntries, so that
+	// callers can react to pressure before eviction starts.
+	HighWaterMark float64
+	OnHighWater   func(size, limit int)
+	OnLowWater    func(size, limit int)
+
+	// OnCallbackError optionally receives the value recovered from a
+	// panic in any of the callbacks above. When it is nil such a panic
+	// propagates to the caller of the cache method; the cache itself is
+	// left consistent, but the operation that ran the callback, such as
+	// an eviction loop in Add, is cut short.
+	OnCallbackError func(recovered interface{})
+
+	// DefaultTTL is the time to live of the values added with Add.
+	// Zero means they never expire.
+	DefaultTTL time.Duration
+
+	// TTLJitter randomizes the TTL of the values added with AddWithTTL,
+	// and with DefaultTTL by Add, AddWithEviction, AddChecked, AddBatch,
+	// AddWeighted, AddDirty, AddWithPriority, Swap, AddIfAbsent, GetOrAdd
+	// and Update for a new key, by up to this fraction of it, so that
+	// values added together do not all expire at once; 0.1 spreads them
+	// over ±10% of the TTL, symmetrically around it. The jitter is drawn from
+	// math/rand when the value is added and is part of its stored expiry.
+	// Zero disables it.
+	TTLJitter float64
+
+	// Loader optionally loads the value of a key missing from the cache
+	// for GetOrLoadSync. ok is false if the key does not exist.
+	Loader func(key Key) (value interface{}, ok bool)
+
+	// RefreshAhead makes SyncCache.Get reload an item with Loader in
+	// the background once this fraction of its TTL has elapsed, such as
+	// 0.8, while still returning the current value. Only one refresh per
+	// key runs at a time. Zero disables it; it has no effect on a Cache
+	// used directly, which is not safe for concurrent access.
+	RefreshAhead float64
+
+	// CacheNegative makes GetOrLoadSync remember keys Loader did not find
+	// as tombstones, so they are not loaded again until the tombstone
+	// expires after NegativeTTL, or is evicted if NegativeTTL is zero.
+	// Tombstones count toward MaxEntries and Len like other items and
+	// are visited by Foreach with a nil value.
+	CacheNegative bool
+	NegativeTTL   time.Duration
+
+	// SlidingTTL makes every read of an item, such as Get, push its
+	// expiry back by the TTL it was added with. By default the expiry is
+	// absolute. MaxAge still applies to sliding items: an item older than
+	// MaxAge expires however recently it was read.
+	SlidingTTL bool
+
+	// MaxAge is the maximum time an entry stays in the cache after it
+	// was first added, whatever its TTL and however often it is read.
+	// Zero means no limit. It may be changed at any time; the next
+	// RemoveExpired then visits every item once to apply it.
+	MaxAge time.Duration
+
+	// MaxBytes is the maximum total cost of the cache entries before
+	// an item is evicted. Zero means no limit. Items only have a cost
+	// when Cost is set or when they are added with AddWeighted.
+	MaxBytes int64
+
+	// Cost optionally returns the cost, usually the size in bytes,
+	// of an entry. It is called once each time a value is added.
+	Cost func(key Key, value interface{}) int64
+
+	// DefaultEntrySize is the size ApproxBytes assumes for a value
+	// that does not implement Sizer.
+	DefaultEntrySize int64
+
+	// KeyFunc optionally normalizes every key passed to the cache before
+	// it is looked up or stored, such as by lowercasing string keys so
+	// that "Example.com" and "example.com" share an entry. The normalized
+	// key is the one stored, returned by Keys and passed to callbacks.
+	// KeyFunc must be deterministic, must return a normalized key
+	// unchanged, and maps every key it merges to the same entry.
+	KeyFunc func(key Key) Key
+
 	Ll    *list.List
 	Cache map[interface{}]*list.Element
+
+	stats  Stats
+	bytes  int64   // total cost of the entries
+	policy policy  // nil means plain LRU
+	admit  *sketch // TinyLFU admission filter, nil if disabled
+
+	deadlines deadlines     // expiries of the entries, for RemoveExpired
+	heapAge   time.Duration // MaxAge the deadlines were scheduled with
+	trackTime bool          // record when entries are read
+
+	insertionOrder bool    // reads do not reorder entries, see WithAccessOrder
+	thrash         *sketch // keys evicted for capacity, for OnThrash
+	highWater      bool    // Len is at or above HighWaterMark
+	prioritized    int     // number of entries with a non-zero priority
+	pooled         bool    // recycle entries, see WithEntryPool
+	subs           []chan EvictEvent
 }
 
 // A Key may be any value that is comparable. See http://golang.org/ref/spec#Comparison_operators
+// A nil Key is never stored: adding a value under it does nothing, or
+// reports false for the methods that return whether the value was stored,
+// and looking it up is always a miss. Values, on the other hand, may be
+// nil; a cached nil value is told apart from a miss by the ok result of
+// Get, never by the value itself.
+// Keys are boxed into interfaces; TypedCache avoids that for hot paths.
 type Key interface{}
 
+// EvictReason tells why an entry left the cache.
+type EvictReason int
+
+const (
+	ReasonDeleted  EvictReason = iota // removed explicitly, e.g. by Remove
+	ReasonCapacity                    // evicted to fit MaxEntries or MaxBytes
+	ReasonExpired                     // its TTL elapsed
+	ReasonCleared                     // removed by Clear
+)
+
+var reasonNames = [...]string{"deleted", "capacity", "expired", "cleared"}
+
+func (r EvictReason) String() string {
+	if r >= 0 && int(r) < len(reasonNames) {
+		return reasonNames[r]
+	}
+	return "EvictReason(" + strconv.Itoa(int(r)) + ")"
+}
+
 type entry struct {
-	key   Key
-	value interface{}
+	key    Key
+	value  interface{}
+	expire time.Time     // zero means the entry never expires
+	ttl    time.Duration // time to live the entry was given, for SlidingTTL
+	added  time.Time     // when the key was first added
+	used   time.Time     // last access
+	cost   int64
+	hits   int      // number of times the entry was read
+	tick   uint64   // logical access time, for policies that need one
+	refs   []uint64 // last logical access times, oldest first, for LRU-K
+	pinned bool     // exempt from capacity eviction
+
+	tombstone bool // caches the absence of the key, see CacheNegative
+	dirty     bool // not written back yet, see AddDirty
+	priority  int  // items of lower priority are evicted first
 }
 
 // New creates a new Cache.
 // If maxEntries is zero, the cache has no limit and it's assumed
 // that eviction is done by the caller.
 func New(maxEntries int) *Cache {
-	return &Cache{
-		MaxEntries: maxEntries,
-		Ll:         list.New(),
-		Cache:      make(map[interface{}]*list.Element),
+	return NewWithOptions(WithMaxEntries(maxEntries))
+}
+
+// NewFromMap creates a new Cache holding the items of m, then evicts
+// items until it fits in maxEntries. The items are added in the order in
+// which the map is ranged over, which is random, so the order of the
+// cache and the evicted items are not predictable; use New and AddBatch
+// to add items in a given order.
+func NewFromMap(maxEntries int, m map[Key]interface{}) *Cache {
+	c := New(maxEntries)
+	expire := c.expireAt(c.DefaultTTL)
+	for key, value := range m {
+		c.set(key, value, expire, c.costOf(key, value))
 	}
+	c.shrink()
+	return c
+}
+
+// NewBounded creates a new Cache holding up to maxEntries items that
+// rejects new keys when it is full instead of evicting, see NoEvict.
+// Full reports whether a new key would be rejected.
+func NewBounded(maxEntries int) *Cache {
+	return NewWithOptions(WithMaxEntries(maxEntries), WithNoEvict(true))
 }
 
 // Add adds a value to the cache.
+// The value expires after DefaultTTL, or never if it is zero, even if
+// key was previously added with another TTL.
+// When the cache has a MaxBytes limit, the oldest items are evicted until
+// the total cost fits in it; a value whose cost alone exceeds MaxBytes is
+// therefore evicted as well, along with every other item; AddChecked
+// rejects such a value instead.
 func (c *Cache) Add(key Key, value interface{}) {
+	c.add(key, value, c.expireAt(c.DefaultTTL))
+}
+
+// AddWithEviction is like Add but also returns the key of the item it
+// evicted to make room, if any. When several items are evicted, as can
+// happen with MaxBytes, the first one is returned.
+func (c *Cache) AddWithEviction(key Key, value interface{}) (evictedKey Key, evicted bool) {
+	return c.add(key, value, c.expireAt(c.DefaultTTL))
+}
+
+func (c *Cache) add(key Key, value interface{}, expire time.Time) (evictedKey Key, evicted bool) {
+	if !c.admits(key) {
+		return nil, false
+	}
+	c.set(key, value, expire, c.costOf(key, value))
+	return c.shrink()
+}
+
+// AddIfAbsent adds a value to the cache only if key is missing.
+// If key is present it is marked as the most recently used item and
+// its current value is returned with loaded set to true; otherwise
+// value is added and returned with loaded set to false. A value the
+// cache refuses to store, as for a nil key (see Key), under NoEvict or
+// by the filter of NewTinyLFU, is also returned with loaded set to
+// false but is not added; use AddChecked to tell.
+func (c *Cache) AddIfAbsent(key Key, value interface{}) (actual interface{}, loaded bool) {
+	if ele, hit := c.lookup(key); hit {
+		c.promote(ele)
+		return ele.Value.(*entry).value, true
+	}
+	c.Add(key, value)
+	return value, false
+}
+
+// Swap is like Add but also returns the value key had, if any. had is
+// false if key was missing or expired. Like Add, it clears the dirty mark
+// and the priority of the item.
+func (c *Cache) Swap(key Key, value interface{}) (previous interface{}, had bool) {
+	if ele, hit := c.lookup(key); hit {
+		previous, had = ele.Value.(*entry).value, true
+	}
+	c.Add(key, value)
+	return previous, had
+}
+
+// Update calls fn with the current value of key, and whether it was found,
+// then stores the value returned by fn as the most recently used item if
+// store is true, keeping the current expiry, dirty mark and priority, or
+// applying DefaultTTL to a new key. If store is false, key is removed from
+// the cache, calling OnEvicted, if it was present.
+// fn must not modify the cache.
+func (c *Cache) Update(key Key, fn func(old interface{}, ok bool) (new interface{}, store bool)) {
+	var old interface{}
+	ele, ok := c.lookup(key)
+	if ok {
+		old = ele.Value.(*entry).value
+	}
+	value, store := fn(old, ok)
+	switch {
+	case store && ok:
+		kv := ele.Value.(*entry)
+		c.replace(kv, value, kv.expire, c.costOf(key, value))
+		c.shrink()
+	case store:
+		c.add(key, value, c.expireAt(c.DefaultTTL))
+	case ok:
+		c.removeElement(ele, ReasonDeleted)
+	}
+}
+
+// AddBatch adds values[i] under keys[i] for every i, in order, so the last
+// pair ends up as the most recently used. The limits are only enforced once
+// every pair has been added. It panics if keys and values differ in length.
+func (c *Cache) AddBatch(keys []Key, values []interface{}) {
+	if len(keys) != len(values) {
+		panic("lru: AddBatch called with keys and values of different lengths")
+	}
+	for i, key := range keys {
+		c.set(key, values[i], c.expireAt(c.DefaultTTL), c.costOf(key, values[i]))
+	}
+	c.shrink()
+}
+
+// Merge adds every unexpired item of other to the cache, from the oldest
+// to the newest with their expiry, so the newest item of other becomes
+// the newest item of c. When a key is in both caches, the stored value is
+// onConflict(key, value in c, value in other), or the value in other if
+// onConflict is nil, and the item keeps its dirty mark and priority in c.
+// The limits of c are enforced once all items are merged; other is left
+// untouched.
+func (c *Cache) Merge(other *Cache, onConflict func(key Key, a, b interface{}) interface{}) {
+	if other == nil || other.Cache == nil || other == c {
+		return
+	}
+	now := time.Now()
+	for ele := other.Ll.Back(); ele != nil; ele = ele.Prev() {
+		kv := ele.Value.(*entry)
+		if kv.tombstone || other.expired(kv, now) {
+			continue
+		}
+		value := kv.value
+		var e *list.Element
+		if cur, ok := c.peek(kv.key); ok {
+			if onConflict != nil {
+				value = onConflict(kv.key, cur.Value.(*entry).value, value)
+			}
+			e = c.replace(cur.Value.(*entry), value, kv.expire, c.costOf(kv.key, value))
+		} else {
+			e = c.set(kv.key, value, kv.expire, c.costOf(kv.key, value))
+		}
+		if e != nil {
+			e.Value.(*entry).ttl = kv.ttl
+		}
+	}
+	c.shrink()
+}
+
+// AddWeighted is like Add but records cost as the cost of the item
+// instead of calling Cost, and evicts the oldest items until the total
+// cost fits in MaxBytes. It returns false, leaving the cache untouched,
+// if cost alone exceeds MaxBytes, if the value does not fit under
+// NoEvict, or if key is nil. It also returns false if the value was
+// evicted right after being added, because only pinned items were left
+// to make room for it. Updating a key adjusts the total cost by the
+// difference with its previous cost.
+func (c *Cache) AddWeighted(key Key, value interface{}, cost int64) bool {
+	if c.MaxBytes > 0 && cost > c.MaxBytes {
+		return false
+	}
+	return c.addStored(key, value, cost)
+}
+
+// AddChecked is like Add but returns false, leaving the cache untouched,
+// when the value is not stored: if its cost according to Cost alone
+// exceeds MaxBytes, where Add would evict every item and then the value
+// itself, if the admission filter of a NewTinyLFU cache rejects it, if
+// the cache is full and NoEvict is set, or if key is nil. Like
+// AddWeighted, it also returns false if pinned items left no room for the
+// value, which was then evicted right away.
+func (c *Cache) AddChecked(key Key, value interface{}) bool {
+	cost := c.costOf(key, value)
+	if c.MaxBytes > 0 && cost > c.MaxBytes {
+		return false
+	}
+	if !c.admits(key) {
+		return false
+	}
+	return c.addStored(key, value, cost)
+}
+
+// addStored adds an item of the given cost as Add does and reports
+// whether it is still in the cache once the limits are enforced.
+func (c *Cache) addStored(key Key, value interface{}, cost int64) bool {
+	ele := c.set(key, value, c.expireAt(c.DefaultTTL), cost)
+	if ele == nil {
+		return false
+	}
+	// Keep the stored key, as the entry may be recycled once evicted.
+	key = ele.Value.(*entry).key
+	c.shrink()
+	return c.Cache[key] == ele
+}
+
+// costOf returns the cost of an item according to Cost.
+func (c *Cache) costOf(key Key, value interface{}) int64 {
+	if c.Cost == nil {
+		return 0
+	}
+	return c.Cost(key, value)
+}
+
+// keyOf returns key normalized by KeyFunc.
+func (c *Cache) keyOf(key Key) Key {
+	if c.KeyFunc == nil {
+		return key
+	}
+	return c.KeyFunc(key)
+}
+
+// replace stores value in the item of e like set, but keeps its dirty
+// mark and priority, for the methods that modify an item rather than add
+// it again.
+func (c *Cache) replace(e *entry, value interface{}, expire time.Time, cost int64) *list.Element {
+	dirty, priority := e.dirty, e.priority
+	ele := c.set(e.key, value, expire, cost)
+	if ele != nil {
+		e.dirty = dirty
+		c.setPriority(e, priority)
+	}
+	return ele
+}
+
+// set stores the value of key as the most recently used item
+// without enforcing the limits of the cache, and returns its element.
+// It returns nil, storing nothing, for a nil key, and under NoEvict if
+// the value would not fit.
+func (c *Cache) set(key Key, value interface{}, expire time.Time, cost int64) *list.Element {
+	key = c.keyOf(key)
+	if key == nil {
+		return nil
+	}
 	if c.Cache == nil {
 		c.Cache = make(map[interface{}]*list.Element)
 		c.Ll = list.New()
 	}
+	if c.NoEvict && !c.fits(key, cost) {
+		c.RemoveExpired()
+		if !c.fits(key, cost) {
+			return nil
+		}
+	}
+	now := time.Now()
+	var ttl time.Duration
+	if !expire.IsZero() {
+		ttl = expire.Sub(now)
+	}
 	if ee, ok := c.Cache[key]; ok {
-		c.Ll.MoveToFront(ee)
-		ee.Value.(*entry).value = value
-		return
+		c.reorder(ee)
+		kv := ee.Value.(*entry)
+		c.bytes += cost - kv.cost
+		old := kv.value
+		kv.value = value
+		if !expire.Equal(kv.expire) {
+			// Keep the TTL of an item stored again with its own expiry,
+			// as by Update, so that SlidingTTL still uses it.
+			kv.expire = expire
+			kv.ttl = ttl
+		}
+		kv.cost = cost
+		kv.tombstone = false
+		kv.dirty = false
+		c.setPriority(kv, 0)
+		c.schedule(ee)
+		if c.OnUpdated != nil {
+			c.callback(func() { c.OnUpdated(key, old, value) })
+		}
+		return ee
 	}
-	ele := c.Ll.PushFront(&entry{key, value})
+	kv := c.newEntry()
+	*kv = entry{key: key, value: value, expire: expire, ttl: ttl, added: now, cost: cost}
+	ele := c.Ll.PushFront(kv)
 	c.Cache[key] = ele
+	c.bytes += cost
+	c.schedule(ele)
+	if c.policy != nil {
+		c.policy.added(c, ele)
+	}
+	if c.OnAdded != nil {
+		c.callback(func() { c.OnAdded(key, value) })
+	}
+	c.readded(key)
+	c.checkWater()
+	return ele
+}
+
+// fits reports whether storing key with cost keeps the cache within
+// its limits.
+func (c *Cache) fits(key Key, cost int64) bool {
+	ele, ok := c.Cache[key]
+	if !ok && c.MaxEntries > 0 && c.Ll.Len() >= c.MaxEntries {
+		return false
+	}
+	if c.MaxBytes <= 0 {
+		return true
+	}
+	if ok {
+		cost -= ele.Value.(*entry).cost
+	}
+	return c.bytes+cost <= c.MaxBytes
+}
+
+// shrink evicts the oldest items until the cache fits in its limits and
+// returns the first evicted key, if any.
+// The newest item is only evicted when it alone exceeds MaxBytes.
+func (c *Cache) shrink() (first Key, evicted bool) {
+	newest := c.Ll.Front()
+	note := func(key Key) {
+		if !evicted {
+			first, evicted = key, true
+		}
+	}
 	if c.MaxEntries != 0 && c.Ll.Len() > c.MaxEntries {
-		c.RemoveOldest()
+		size := c.MaxEntries
+		if c.EvictBatch > 1 {
+			size -= c.EvictBatch - 1
+		}
+		for c.Ll.Len() > size {
+			key, _, ok := c.evict(newest)
+			if !ok {
+				break
+			}
+			note(key)
+		}
+	}
+	for c.MaxBytes > 0 && c.bytes > c.MaxBytes {
+		key, _, ok := c.evict(newest)
+		if !ok {
+			if key, _, ok = c.evict(nil); !ok {
+				return
+			}
+		}
+		note(key)
 	}
+	return
 }
 
 // Get looks up a key's value from the cache.
+// An expired item is removed and reported as a miss. ok is true for a
+// cached nil value.
 func (c *Cache) Get(key Key) (value interface{}, ok bool) {
+	if ele, hit := c.get(key); hit {
+		return ele.Value.(*entry).value, true
+	}
+	return
+}
+
+// GetMulti looks up the values of keys as Get does and returns the
+// found ones.
+func (c *Cache) GetMulti(keys []Key) map[Key]interface{} {
+	m := make(map[Key]interface{}, len(keys))
+	for _, key := range keys {
+		if value, ok := c.Get(key); ok {
+			m[key] = value
+		}
+	}
+	return m
+}
+
+// GetQuiet is like Get, including updating recent-ness, but is not
+// counted in Stats. It is meant for inspection traffic that should not
+// skew the hit ratio.
+func (c *Cache) GetQuiet(key Key) (value interface{}, ok bool) {
+	if ele, hit := c.lookup(key); hit {
+		c.promote(ele)
+		return ele.Value.(*entry).value, true
+	}
+	return
+}
+
+// get looks up the element of key as Get does, counting a hit or a miss
+// and promoting the element.
+func (c *Cache) get(key Key) (*list.Element, bool) {
+	if c.admit != nil {
+		c.admit.add(c.keyOf(key))
+	}
+	ele, hit := c.lookup(key)
+	if !hit {
+		c.stats.Misses++
+		return nil, false
+	}
+	c.stats.Hits++
+	c.promote(ele)
+	return ele, true
+}
+
+// GetOrAdd looks up a key's value from the cache, or calls loader and
+// adds its result if the key is missing. loaded reports whether the value
+// was already cached. A nil value returned by loader is cached like any
+// other value. The result of loader is returned but not cached when the
+// cache refuses to store it, as for a nil key (see Key), under NoEvict
+// or by the filter of NewTinyLFU.
+func (c *Cache) GetOrAdd(key Key, loader func() interface{}) (value interface{}, loaded bool) {
+	if value, ok := c.Get(key); ok {
+		return value, true
+	}
+	value = loader()
+	c.Add(key, value)
+	return value, false
+}
+
+// Touch marks key as the most recently used item without reading its
+// value. It reports whether key was in the cache.
+func (c *Cache) Touch(key Key) bool {
+	ele, hit := c.lookup(key)
+	if hit {
+		c.promote(ele)
+	}
+	return hit
+}
+
+// Peek looks up a key's value from the cache without updating
+// its recent-ness. An expired item is removed and reported as a miss.
+func (c *Cache) Peek(key Key) (value interface{}, ok bool) {
+	if ele, hit := c.lookup(key); hit {
+		return ele.Value.(*entry).value, true
+	}
+	return
+}
+
+// Contains reports whether key is in the cache without updating
+// its recent-ness. An expired item is reported as absent but not removed.
+func (c *Cache) Contains(key Key) bool {
+	_, hit := c.peek(key)
+	return hit
+}
+
+// GetOldest returns the oldest item of the cache, the next one to be
+// evicted, without updating its recent-ness.
+func (c *Cache) GetOldest() (key Key, value interface{}, ok bool) {
 	if c.Cache == nil {
 		return
 	}
-	if ele, hit := c.Cache[key]; hit {
-		c.Ll.MoveToFront(ele)
-		return ele.Value.(*entry).value, true
+	if ele := c.Ll.Back(); ele != nil {
+		kv := ele.Value.(*entry)
+		return kv.key, kv.value, true
+	}
+	return
+}
+
+// GetNewest returns the most recently used item of the cache without
+// updating its recent-ness.
+func (c *Cache) GetNewest() (key Key, value interface{}, ok bool) {
+	if c.Cache == nil {
+		return
+	}
+	if ele := c.Ll.Front(); ele != nil {
+		kv := ele.Value.(*entry)
+		return kv.key, kv.value, true
 	}
 	return
 }
@@ -75,30 +675,355 @@ func (c *Cache) Remove(key Key) {
 	if c.Cache == nil {
 		return
 	}
-	if ele, hit := c.Cache[key]; hit {
-		c.removeElement(ele)
+	if ele, hit := c.Cache[c.keyOf(key)]; hit {
+		c.removeElement(ele, ReasonDeleted)
+	}
+}
+
+// RemoveMulti removes the provided keys from the cache and returns the
+// number of keys that were present.
+func (c *Cache) RemoveMulti(keys []Key) int {
+	if c.Cache == nil {
+		return 0
+	}
+	removed := 0
+	for _, key := range keys {
+		if ele, hit := c.Cache[c.keyOf(key)]; hit {
+			c.removeElement(ele, ReasonDeleted)
+			removed++
+		}
+	}
+	return removed
+}
+
+// RemoveFunc removes every item for which match returns true, calling
+// OnEvicted for each of them, and returns the number of removed items.
+// match is called from the oldest to the newest item and must not
+// modify the cache.
+func (c *Cache) RemoveFunc(match func(key Key, value interface{}) bool) int {
+	if c.Cache == nil {
+		return 0
+	}
+	removed := 0
+	for ele := c.Ll.Back(); ele != nil; {
+		prev := ele.Prev()
+		if kv := ele.Value.(*entry); match(kv.key, kv.value) {
+			c.removeElement(ele, ReasonDeleted)
+			removed++
+		}
+		ele = prev
 	}
+	return removed
 }
 
-// RemoveOldest removes the oldest item from the cache.
+// RemoveOldest removes the oldest item from the cache, or the item the
+// eviction policy of the cache picks, such as the least frequently used
+// for NewLFU. Pinned items are skipped. It counts as an eviction in Stats.
 func (c *Cache) RemoveOldest() Key {
+	key, _, _ := c.evict(nil)
+	return key
+}
+
+// evict removes the next victim of the cache other than skip, if any,
+// as an eviction.
+func (c *Cache) evict(skip *list.Element) (key Key, value interface{}, ok bool) {
 	if c.Cache == nil {
-		return nil
+		return nil, nil, false
 	}
-	ele := c.Ll.Back()
-	if ele != nil {
-		c.removeElement(ele)
-		return ele.Value.(*entry).key
+	ele := c.victim(skip)
+	if ele == nil {
+		return nil, nil, false
+	}
+	c.stats.Evictions++
+	key, value = c.removeElement(ele, ReasonCapacity)
+	return key, value, true
+}
+
+// RemoveOldestN removes up to n items as RemoveOldest does and returns
+// their keys in eviction order.
+func (c *Cache) RemoveOldestN(n int) []Key {
+	if n > c.Len() {
+		n = c.Len()
+	}
+	if n < 0 {
+		n = 0
+	}
+	keys := make([]Key, 0, n)
+	for len(keys) < n {
+		key, _, ok := c.evict(nil)
+		if !ok {
+			break
+		}
+		keys = append(keys, key)
+	}
+	return keys
+}
+
+// PopOldest removes the oldest item from the cache and returns it.
+// OnEvicted is called as for Remove, and it is not counted as an eviction.
+func (c *Cache) PopOldest() (key Key, value interface{}, ok bool) {
+	if c.Cache == nil {
+		return
+	}
+	return c.pop(c.Ll.Back())
+}
+
+// PopOldestFunc removes the oldest item for which match returns true and
+// returns it, ok being false if there is none. OnEvicted is called as
+// for Remove. match must not modify the cache.
+func (c *Cache) PopOldestFunc(match func(key Key, value interface{}) bool) (key Key, value interface{}, ok bool) {
+	if c.Cache == nil {
+		return
+	}
+	for ele := c.Ll.Back(); ele != nil; ele = ele.Prev() {
+		if kv := ele.Value.(*entry); match(kv.key, kv.value) {
+			return c.pop(ele)
+		}
+	}
+	return
+}
+
+// PopNewest removes the newest item from the cache and returns it.
+// OnEvicted is called as for Remove.
+func (c *Cache) PopNewest() (key Key, value interface{}, ok bool) {
+	if c.Cache == nil {
+		return
+	}
+	return c.pop(c.Ll.Front())
+}
+
+func (c *Cache) pop(ele *list.Element) (key Key, value interface{}, ok bool) {
+	if ele == nil {
+		return
+	}
+	key, value = c.removeElement(ele, ReasonDeleted)
+	return key, value, true
+}
+
+// Pin exempts key from capacity eviction until Unpin is called.
+// It reports whether key was in the cache.
+// A pinned item is still removed by Remove, Clear or when it expires.
+// When only pinned items are left the cache grows past MaxEntries, but
+// still evicts the item just added to stay within MaxBytes.
+func (c *Cache) Pin(key Key) bool {
+	return c.setPinned(key, true)
+}
+
+// Unpin makes key subject to capacity eviction again.
+// It reports whether key was in the cache.
+func (c *Cache) Unpin(key Key) bool {
+	return c.setPinned(key, false)
+}
+
+func (c *Cache) setPinned(key Key, pinned bool) bool {
+	ele, hit := c.lookup(key)
+	if hit {
+		ele.Value.(*entry).pinned = pinned
+	}
+	return hit
+}
+
+// EvictedEntry is an item evicted by Resize.
+type EvictedEntry struct {
+	Key   Key
+	Value interface{}
+}
+
+// Resize changes MaxEntries and evicts the oldest items until the cache
+// fits in the new limit, calling OnEvicted for each of them. It returns
+// the evicted items in eviction order, so that a caller without a
+// callback can process them; the slice is empty if nothing was evicted.
+// A zero maxEntries removes the limit and evicts nothing.
+func (c *Cache) Resize(maxEntries int) []EvictedEntry {
+	c.MaxEntries = maxEntries
+	evicted := []EvictedEntry{}
+	if maxEntries == 0 {
+		return evicted
+	}
+	for c.Len() > maxEntries {
+		key, value, ok := c.evict(nil)
+		if !ok {
+			break
+		}
+		evicted = append(evicted, EvictedEntry{key, value})
+	}
+	return evicted
+}
+
+// PruneTo evicts the oldest items until the cache holds at most size of
+// them and returns the number of evicted items. Unlike Resize it leaves
+// MaxEntries untouched. A zero or negative size evicts every item but
+// the pinned ones.
+func (c *Cache) PruneTo(size int) (evicted int) {
+	if size < 0 {
+		size = 0
+	}
+	for c.Len() > size {
+		if _, _, ok := c.evict(nil); !ok {
+			break
+		}
+		evicted++
+	}
+	return evicted
+}
+
+// Clear removes all items from the cache, calling OnEvicted for each
+// of them from the oldest to the newest.
+// The cache starts over with a new map and list; see Reset to reuse them.
+func (c *Cache) Clear() {
+	if c.Cache != nil {
+		for ele := c.Ll.Back(); ele != nil; ele = ele.Prev() {
+			c.evicted(ele.Value.(*entry), ReasonCleared)
+		}
+	}
+	c.Ll = list.New()
+	c.Cache = make(map[interface{}]*list.Element)
+	c.bytes = 0
+	c.deadlines = nil
+	c.prioritized = 0
+	c.checkWater()
+}
+
+// Reset removes all items from the cache like Clear, calling OnEvicted
+// for each of them, but keeps the memory of its map and list for the
+// items added next, so a cache that is repeatedly filled to a similar
+// size and emptied does not reallocate it each time. Clear instead
+// drops that memory, and Compact shrinks it to fit the current items.
+func (c *Cache) Reset() {
+	if c.Cache == nil {
+		return
+	}
+	for ele := c.Ll.Back(); ele != nil; ele = ele.Prev() {
+		c.evicted(ele.Value.(*entry), ReasonCleared)
+	}
+	for ele := c.Ll.Front(); ele != nil; ele = ele.Next() {
+		c.release(ele.Value.(*entry))
+	}
+	for key := range c.Cache {
+		delete(c.Cache, key)
+	}
+	c.Ll.Init()
+	c.bytes = 0
+	for i := range c.deadlines {
+		c.deadlines[i] = deadline{}
+	}
+	c.deadlines = c.deadlines[:0]
+	c.prioritized = 0
+	c.checkWater()
+}
+
+// Compact rebuilds the map of the cache to fit its current length, so
+// that memory held after many removals is released. The order of the
+// items is kept and no callback is called. It is O(n) and meant for
+// occasional maintenance, such as after a burst, not every operation.
+func (c *Cache) Compact() {
+	if c.Cache == nil {
+		return
+	}
+	m := make(map[interface{}]*list.Element, c.Ll.Len())
+	for ele := c.Ll.Front(); ele != nil; ele = ele.Next() {
+		m[ele.Value.(*entry).key] = ele
+	}
+	c.Cache = m
+	c.compactDeadlines()
+}
+
+// promote records an access to e and moves it according to the policy.
+func (c *Cache) promote(e *list.Element) {
+	kv := e.Value.(*entry)
+	kv.hits++
+	sliding := c.SlidingTTL && kv.ttl > 0
+	if c.trackTime || sliding {
+		now := time.Now()
+		if c.trackTime {
+			kv.used = now
+		}
+		if sliding {
+			kv.expire = now.Add(kv.ttl)
+			c.schedule(e)
+		}
+	}
+	if !c.insertionOrder {
+		c.reorder(e)
+	}
+}
+
+// reorder moves e as the most recently used item according to the policy.
+func (c *Cache) reorder(e *list.Element) {
+	if c.policy != nil {
+		c.policy.access(c, e)
+		return
+	}
+	c.Ll.MoveToFront(e)
+}
+
+// victim returns the element to evict next other than skip,
+// or nil if there is none.
+func (c *Cache) victim(skip *list.Element) *list.Element {
+	if c.policy != nil {
+		return c.policy.victim(c, skip)
+	}
+	if c.prioritized > 0 {
+		return c.lowestPriority(skip)
+	}
+	return c.oldestUnpinned(skip)
+}
+
+// oldestUnpinned returns the element nearest to the back of Ll that
+// is neither pinned nor skip, or nil.
+func (c *Cache) oldestUnpinned(skip *list.Element) *list.Element {
+	for e := c.Ll.Back(); e != nil; e = e.Prev() {
+		if e != skip && !e.Value.(*entry).pinned {
+			return e
+		}
 	}
 	return nil
 }
 
-func (c *Cache) removeElement(e *list.Element) {
+// removeElement removes e from the cache and returns its key and value,
+// so callers don't have to read the removed element afterwards.
+func (c *Cache) removeElement(e *list.Element, reason EvictReason) (key Key, value interface{}) {
 	c.Ll.Remove(e)
 	kv := e.Value.(*entry)
 	delete(c.Cache, kv.key)
+	c.bytes -= kv.cost
+	c.setPriority(kv, 0)
+	c.evicted(kv, reason)
+	c.checkWater()
+	key, value = kv.key, kv.value
+	c.release(kv)
+	return key, value
+}
+
+// callback runs the user callback fn, recovering a panic into
+// OnCallbackError when it is set.
+func (c *Cache) callback(fn func()) {
+	if c.OnCallbackError != nil {
+		defer func() {
+			if r := recover(); r != nil {
+				c.OnCallbackError(r)
+			}
+		}()
+	}
+	fn()
+}
+
+// evicted runs the eviction callbacks for an entry that left the cache.
+func (c *Cache) evicted(kv *entry, reason EvictReason) {
 	if c.OnEvicted != nil {
-		c.OnEvicted(kv.key, kv.value)
+		c.callback(func() { c.OnEvicted(kv.key, kv.value) })
+	}
+	if c.OnEvictedReason != nil {
+		c.callback(func() { c.OnEvictedReason(kv.key, kv.value, reason) })
+	}
+	if len(c.subs) != 0 {
+		c.publish(EvictEvent{kv.key, kv.value, reason})
+	}
+	if kv.dirty && reason != ReasonDeleted && c.OnDirtyEvicted != nil {
+		c.callback(func() { c.OnDirtyEvicted(kv.key, kv.value) })
+	}
+	if reason == ReasonCapacity {
+		c.evictedForCapacity(kv.key)
 	}
 }
 
@@ -110,7 +1035,117 @@ func (c *Cache) Len() int {
 	return c.Ll.Len()
 }
 
+// Cap returns the maximum number of items of the cache, MaxEntries.
+// Zero means no limit.
+func (c *Cache) Cap() int {
+	return c.MaxEntries
+}
+
+// Full reports whether the cache holds MaxEntries items or more.
+// An unlimited cache is never full.
+func (c *Cache) Full() bool {
+	return c.MaxEntries != 0 && c.Len() >= c.MaxEntries
+}
+
+// Keys returns the keys in the cache ordered from the oldest (next to be
+// evicted) to the newest (most recently used).
+// An empty cache returns an empty, non-nil slice.
+func (c *Cache) Keys() []Key {
+	if c.Cache == nil {
+		return []Key{}
+	}
+	keys := make([]Key, 0, c.Ll.Len())
+	for ele := c.Ll.Back(); ele != nil; ele = ele.Prev() {
+		keys = append(keys, ele.Value.(*entry).key)
+	}
+	return keys
+}
+
+// OldestN returns up to n keys from the oldest, next to be evicted by
+// plain LRU, to the newest, without changing the cache. An empty cache
+// or a non-positive n returns an empty, non-nil slice.
+func (c *Cache) OldestN(n int) []Key {
+	keys := make([]Key, 0, c.boundedLen(n))
+	if c.Cache == nil {
+		return keys
+	}
+	for ele := c.Ll.Back(); ele != nil && len(keys) < n; ele = ele.Prev() {
+		keys = append(keys, ele.Value.(*entry).key)
+	}
+	return keys
+}
+
+// NewestN returns up to n keys from the newest, most recently used, to
+// the oldest, without changing the cache. An empty cache or a
+// non-positive n returns an empty, non-nil slice.
+func (c *Cache) NewestN(n int) []Key {
+	keys := make([]Key, 0, c.boundedLen(n))
+	if c.Cache == nil {
+		return keys
+	}
+	for ele := c.Ll.Front(); ele != nil && len(keys) < n; ele = ele.Next() {
+		keys = append(keys, ele.Value.(*entry).key)
+	}
+	return keys
+}
+
+// boundedLen returns n clamped to [0, Len()].
+func (c *Cache) boundedLen(n int) int {
+	if n > c.Len() {
+		return c.Len()
+	}
+	if n < 0 {
+		return 0
+	}
+	return n
+}
+
+// CountFunc returns the number of items for which match returns true,
+// without updating their recent-ness.
+func (c *Cache) CountFunc(match func(key Key, value interface{}) bool) int {
+	if c.Cache == nil {
+		return 0
+	}
+	n := 0
+	for ele := c.Ll.Back(); ele != nil; ele = ele.Prev() {
+		if kv := ele.Value.(*entry); match(kv.key, kv.value) {
+			n++
+		}
+	}
+	return n
+}
+
+// Snapshot returns a copy of the unexpired items of the cache as a map.
+// It neither updates recent-ness nor calls any callback.
+func (c *Cache) Snapshot() map[Key]interface{} {
+	m := make(map[Key]interface{}, c.Len())
+	if c.Cache == nil {
+		return m
+	}
+	now := time.Now()
+	for ele := c.Ll.Back(); ele != nil; ele = ele.Prev() {
+		if kv := ele.Value.(*entry); !kv.tombstone && !c.expired(kv, now) {
+			m[kv.key] = kv.value
+		}
+	}
+	return m
+}
+
+// ForeachOrdered walks the cache from the newest item if newestFirst is
+// true, like ForeachNewest, or from the oldest one otherwise, like Foreach.
+// Returning true from fn stops the walk.
+func (c *Cache) ForeachOrdered(newestFirst bool, fn func(Key, interface{}) bool) {
+	if newestFirst {
+		c.ForeachNewest(fn)
+	} else {
+		c.Foreach(fn)
+	}
+}
+
 // Foreach foreach the oldest item from the cache.
+// It walks from the oldest (Ll.Back) to the newest item, use ForeachNewest
+// to walk the other way.
+// fn must not modify the cache, see ForeachMutable.
 //fn return args
 //arg1:if true break foreach,or continue foreach
 func (c *Cache) Foreach(fn func(Key, interface{}) bool) {
@@ -126,6 +1161,23 @@ func (c *Cache) Foreach(fn func(Key, interface{}) bool) {
 	}
 }
 
+// ForeachNewest foreach the newest item from the cache.
+// It walks from the newest (Ll.Front) to the oldest item, the reverse
+// of Foreach.
+//fn return args
+//arg1:if true break foreach,or continue foreach
+func (c *Cache) ForeachNewest(fn func(Key, interface{}) bool) {
+	if c.Cache == nil {
+		return
+	}
+	for ele := c.Ll.Front(); ele != nil; ele = ele.Next() {
+		entry := ele.Value.(*entry)
+		if fn(entry.key, entry.value) {
+			break
+		}
+	}
+}
+
 // Foreach foreach the oldest item from the cache.
 //fn return args
 //arg1:true break foreach,or continue foreach.
@@ -144,7 +1196,7 @@ func (c *Cache) RemoveForeach(fn func(Key, interface{}) (bool, bool)) {
 			break
 		}
 		if remove {
-			c.removeElement(oldEle)
+			c.removeElement(oldEle, ReasonDeleted)
 		}
 	}
 }
@@ -0,0 +1,116 @@
+// Copyright 2016 zxfonline@sina.com. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package lru
+
+import "testing"
+
+func TestNilKey(t *testing.T) {
+	tests := []struct {
+		name string
+		op   func(c *Cache) bool // reports whether the op misbehaved
+	}{
+		{"Add", func(c *Cache) bool { c.Add(nil, 1); return c.Len() != 0 }},
+		{"AddChecked", func(c *Cache) bool { return c.AddChecked(nil, 1) || c.Len() != 0 }},
+		{"AddIfAbsent", func(c *Cache) bool {
+			v, loaded := c.AddIfAbsent(nil, 1)
+			return v != 1 || loaded || c.Len() != 0
+		}},
+		{"GetOrAdd", func(c *Cache) bool {
+			v, loaded := c.GetOrAdd(nil, func() interface{} { return 1 })
+			return v != 1 || loaded || c.Len() != 0
+		}},
+		{"Get", func(c *Cache) bool { _, ok := c.Get(nil); return ok }},
+		{"Contains", func(c *Cache) bool { return c.Contains(nil) }},
+		{"Remove", func(c *Cache) bool { c.Remove(nil); return c.Len() != 1 }},
+	}
+	for _, tt := range tests {
+		c := New(2)
+		if tt.name == "Remove" {
+			c.Add("a", 1)
+		}
+		if tt.op(c) {
+			t.Errorf("%s: nil key was not rejected, Keys() = %v", tt.name, c.Keys())
+		}
+	}
+}
+
+func TestNilValue(t *testing.T) {
+	c := New(2)
+	c.Add("a", nil)
+	if v, ok := c.Get("a"); !ok || v != nil {
+		t.Fatalf("Get(a) = %v, %v, want nil, true", v, ok)
+	}
+	if _, ok := c.Get("b"); ok {
+		t.Fatal("Get(b) hit")
+	}
+}
+
+func TestMaxBytesWithPinnedItems(t *testing.T) {
+	c := NewWithOptions(WithMaxEntries(2), WithMaxBytes(10),
+		WithCost(func(key Key, value interface{}) int64 { return int64(value.(int)) }))
+	c.AddWeighted("a", 1, 1)
+	c.AddWeighted("b", 1, 1)
+	c.Pin("a")
+	c.Pin("b")
+	if c.AddWeighted("c", 1, 9) {
+		t.Error("AddWeighted(c) = true for an evicted value")
+	}
+	if c.Contains("c") {
+		t.Error("c is still in the cache")
+	}
+	if c.AddChecked("d", 9) {
+		t.Error("AddChecked(d) = true for an evicted value")
+	}
+	if got := c.Stats().Bytes; got > 10 {
+		t.Fatalf("Bytes = %d, want at most 10", got)
+	}
+	if !c.Contains("a") || !c.Contains("b") {
+		t.Fatalf("pinned items were evicted, Keys() = %v", c.Keys())
+	}
+}
+
+func TestModifyKeepsDirtyAndPriority(t *testing.T) {
+	inc := func(old interface{}, ok bool) (interface{}, bool) { return old.(int) + 1, true }
+	tests := []struct {
+		name string
+		op   func(c *Cache, key Key)
+		keep bool
+	}{
+		{"Update", func(c *Cache, key Key) { c.Update(key, inc) }, true},
+		{"Merge", func(c *Cache, key Key) {
+			other := New(0)
+			other.Add(key, 2)
+			c.Merge(other, nil)
+		}, true},
+		{"Add", func(c *Cache, key Key) { c.Add(key, 2) }, false},
+		{"Swap", func(c *Cache, key Key) { c.Swap(key, 2) }, false},
+	}
+	for _, tt := range tests {
+		c := New(0)
+		c.AddDirty("a", 1)
+		c.AddWithPriority("b", 1, 5)
+		tt.op(c, "a")
+		tt.op(c, "b")
+		var flushed map[Key]interface{}
+		c.Flush(func(m map[Key]interface{}) error { flushed = m; return nil })
+		if _, dirty := flushed["a"]; dirty != tt.keep {
+			t.Errorf("%s: a dirty = %v, want %v", tt.name, dirty, tt.keep)
+		}
+		if p := c.Cache["b"].Value.(*entry).priority; (p == 5) != tt.keep {
+			t.Errorf("%s: priority of b = %d", tt.name, p)
+		}
+	}
+}
+
+func TestUpdateKeepsPriorityForEviction(t *testing.T) {
+	c := New(2)
+	c.AddWithPriority("b", 1, 5)
+	c.Update("b", func(old interface{}, ok bool) (interface{}, bool) { return old.(int) + 1, true })
+	c.Add("a", 1)
+	c.Add("c", 1)
+	if !c.Contains("b") || c.Contains("a") {
+		t.Fatalf("keys = %v, want a evicted before b", c.Keys())
+	}
+}
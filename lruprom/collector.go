@@ -0,0 +1,72 @@
+// Copyright 2016 zxfonline@sina.com. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package lruprom exposes the statistics of an lru cache as Prometheus
+// metrics. It lives in its own package so that lru itself does not
+// depend on the Prometheus client.
+package lruprom
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/zxfonline/lru"
+)
+
+// Source is the part of a cache a collector reads, implemented by
+// lru.SyncCache. An lru.Cache also implements it but is not safe to
+// scrape while it is used from other goroutines.
+type Source interface {
+	Stats() lru.Stats
+	Len() int
+	Cap() int
+}
+
+type collector struct {
+	src Source
+
+	hits      *prometheus.Desc
+	misses    *prometheus.Desc
+	evictions *prometheus.Desc
+	entries   *prometheus.Desc
+	capacity  *prometheus.Desc
+	bytes     *prometheus.Desc
+}
+
+// NewCollector returns a collector exposing the statistics of src as
+// metrics prefixed with name:
+//
+//	name_hits_total, name_misses_total, name_evictions_total (counters)
+//	name_entries, name_capacity, name_bytes (gauges)
+//
+// The counters come from Stats, so calling ResetStats on src makes
+// them go down, which Prometheus treats as a counter reset.
+func NewCollector(name string, src Source) prometheus.Collector {
+	return &collector{
+		src:       src,
+		hits:      prometheus.NewDesc(name+"_hits_total", "Number of lookups that found the key.", nil, nil),
+		misses:    prometheus.NewDesc(name+"_misses_total", "Number of lookups that did not find the key.", nil, nil),
+		evictions: prometheus.NewDesc(name+"_evictions_total", "Number of items evicted to fit the limits.", nil, nil),
+		entries:   prometheus.NewDesc(name+"_entries", "Number of items in the cache.", nil, nil),
+		capacity:  prometheus.NewDesc(name+"_capacity", "Maximum number of items, zero if unlimited.", nil, nil),
+		bytes:     prometheus.NewDesc(name+"_bytes", "Total cost of the items.", nil, nil),
+	}
+}
+
+func (c *collector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- c.hits
+	ch <- c.misses
+	ch <- c.evictions
+	ch <- c.entries
+	ch <- c.capacity
+	ch <- c.bytes
+}
+
+func (c *collector) Collect(ch chan<- prometheus.Metric) {
+	s := c.src.Stats()
+	ch <- prometheus.MustNewConstMetric(c.hits, prometheus.CounterValue, float64(s.Hits))
+	ch <- prometheus.MustNewConstMetric(c.misses, prometheus.CounterValue, float64(s.Misses))
+	ch <- prometheus.MustNewConstMetric(c.evictions, prometheus.CounterValue, float64(s.Evictions))
+	ch <- prometheus.MustNewConstMetric(c.entries, prometheus.GaugeValue, float64(c.src.Len()))
+	ch <- prometheus.MustNewConstMetric(c.capacity, prometheus.GaugeValue, float64(c.src.Cap()))
+	ch <- prometheus.MustNewConstMetric(c.bytes, prometheus.GaugeValue, float64(s.Bytes))
+}
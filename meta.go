@@ -0,0 +1,79 @@
+// Copyright 2016 zxfonline@sina.com. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package lru
+
+import (
+	"sort"
+	"time"
+)
+
+// EntryMeta holds the read-only metadata of a cache entry.
+type EntryMeta struct {
+	AccessCount int       // number of reads, such as Get, since it was added
+	CreatedAt   time.Time // when the key was first added
+	LastAccess  time.Time // time of the last read, zero if never read
+}
+
+// GetWithMeta is like Get but also returns the metadata of the entry,
+// this lookup included. LastAccess is only recorded by a cache created
+// with WithTrackTime and is zero otherwise.
+func (c *Cache) GetWithMeta(key Key) (value interface{}, meta EntryMeta, ok bool) {
+	if ele, hit := c.get(key); hit {
+		kv := ele.Value.(*entry)
+		return kv.value, kv.meta(), true
+	}
+	return
+}
+
+// EntryInfo describes a cache entry, see EntriesByAge.
+type EntryInfo struct {
+	Key Key
+	EntryMeta
+	ExpireAt time.Time // zero if the entry never expires
+}
+
+// EntriesByAge returns the metadata of the unexpired items of the cache,
+// tombstones excluded, from the oldest to the newest by CreatedAt, without
+// updating their recent-ness. Entries with the same CreatedAt keep
+// their order from the least to the most recently used.
+func (c *Cache) EntriesByAge() []EntryInfo {
+	infos := []EntryInfo{}
+	if c.Cache == nil {
+		return infos
+	}
+	for ele := c.Ll.Back(); ele != nil; ele = ele.Prev() {
+		if kv := ele.Value.(*entry); !kv.tombstone && !c.expiredNow(kv) {
+			infos = append(infos, EntryInfo{kv.key, kv.meta(), kv.expire})
+		}
+	}
+	sort.SliceStable(infos, func(i, j int) bool {
+		return infos[i].CreatedAt.Before(infos[j].CreatedAt)
+	})
+	return infos
+}
+
+func (e *entry) meta() EntryMeta {
+	return EntryMeta{
+		AccessCount: e.hits,
+		CreatedAt:   e.added,
+		LastAccess:  e.used,
+	}
+}
+
+// GetWithMeta is like Get but also returns the metadata of the entry,
+// see Cache.GetWithMeta.
+func (c *SyncCache) GetWithMeta(key Key) (value interface{}, meta EntryMeta, ok bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.c.GetWithMeta(key)
+}
+
+// EntriesByAge returns the metadata of the unexpired items of the cache,
+// see Cache.EntriesByAge.
+func (c *SyncCache) EntriesByAge() []EntryInfo {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.c.EntriesByAge()
+}
@@ -0,0 +1,154 @@
+// Copyright 2016 zxfonline@sina.com. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package lru
+
+import (
+	"container/list"
+	"time"
+)
+
+// An Option configures a Cache created by NewWithOptions.
+type Option func(*Cache)
+
+// NewWithOptions creates a new Cache configured by opts.
+// Without options the cache has no limit.
+func NewWithOptions(opts ...Option) *Cache {
+	c := &Cache{
+		Ll:    list.New(),
+		Cache: make(map[interface{}]*list.Element),
+	}
+	for _, opt := range opts {
+		opt(c)
+	}
+	return c
+}
+
+// NewSyncWithOptions creates a new SyncCache configured by opts.
+func NewSyncWithOptions(opts ...Option) *SyncCache {
+	return &SyncCache{c: NewWithOptions(opts...)}
+}
+
+// WithMaxEntries sets MaxEntries.
+func WithMaxEntries(n int) Option {
+	return func(c *Cache) { c.MaxEntries = n }
+}
+
+// WithOnEvicted sets OnEvicted.
+func WithOnEvicted(fn func(key Key, value interface{})) Option {
+	return func(c *Cache) { c.OnEvicted = fn }
+}
+
+// WithOnEvictedReason sets OnEvictedReason.
+func WithOnEvictedReason(fn func(key Key, value interface{}, reason EvictReason)) Option {
+	return func(c *Cache) { c.OnEvictedReason = fn }
+}
+
+// WithDefaultTTL sets DefaultTTL.
+func WithDefaultTTL(d time.Duration) Option {
+	return func(c *Cache) { c.DefaultTTL = d }
+}
+
+// WithTTLJitter sets TTLJitter.
+func WithTTLJitter(fraction float64) Option {
+	return func(c *Cache) { c.TTLJitter = fraction }
+}
+
+// WithTrackTime makes the cache record when each entry was last read,
+// as reported by GetWithMeta. It costs a clock read on every Get, so it
+// is off by default.
+func WithTrackTime(on bool) Option {
+	return func(c *Cache) { c.trackTime = on }
+}
+
+// WithEvictBatch sets EvictBatch.
+func WithEvictBatch(n int) Option {
+	return func(c *Cache) { c.EvictBatch = n }
+}
+
+// WithNoEvict sets NoEvict.
+func WithNoEvict(on bool) Option {
+	return func(c *Cache) { c.NoEvict = on }
+}
+
+// WithAccessOrder sets whether reads reorder the cache, which is the
+// default. With WithAccessOrder(false) the items stay in insertion order,
+// like a LinkedHashMap created without access order: only Add and the
+// other methods that store a value, for a new key or an update, make an
+// item the newest; Get, Touch and every other read do not move it.
+// Eviction and Foreach then follow that order.
+func WithAccessOrder(accessOrder bool) Option {
+	return func(c *Cache) { c.insertionOrder = !accessOrder }
+}
+
+// WithMaxAge sets MaxAge.
+func WithMaxAge(d time.Duration) Option {
+	return func(c *Cache) { c.MaxAge = d }
+}
+
+// WithMaxBytes sets MaxBytes.
+func WithMaxBytes(b int64) Option {
+	return func(c *Cache) { c.MaxBytes = b }
+}
+
+// WithDefaultEntrySize sets DefaultEntrySize.
+func WithDefaultEntrySize(n int64) Option {
+	return func(c *Cache) { c.DefaultEntrySize = n }
+}
+
+// WithKeyFunc sets KeyFunc.
+func WithKeyFunc(fn func(key Key) Key) Option {
+	return func(c *Cache) { c.KeyFunc = fn }
+}
+
+// WithCost sets Cost.
+func WithCost(fn func(key Key, value interface{}) int64) Option {
+	return func(c *Cache) { c.Cost = fn }
+}
+
+// WithLoader sets Loader.
+func WithLoader(fn func(key Key) (value interface{}, ok bool)) Option {
+	return func(c *Cache) { c.Loader = fn }
+}
+
+// WithRefreshAhead sets RefreshAhead.
+func WithRefreshAhead(fraction float64) Option {
+	return func(c *Cache) { c.RefreshAhead = fraction }
+}
+
+// WithNegativeCaching sets CacheNegative, with tombstones expiring
+// after ttl.
+func WithNegativeCaching(ttl time.Duration) Option {
+	return func(c *Cache) {
+		c.CacheNegative = true
+		c.NegativeTTL = ttl
+	}
+}
+
+// WithOnThrash sets OnThrash and ThrashThreshold.
+func WithOnThrash(threshold int, fn func(key Key, count int)) Option {
+	return func(c *Cache) {
+		c.ThrashThreshold = threshold
+		c.OnThrash = fn
+	}
+}
+
+// WithHighWater sets HighWaterMark, OnHighWater and OnLowWater.
+func WithHighWater(mark float64, onHigh, onLow func(size, limit int)) Option {
+	return func(c *Cache) {
+		c.HighWaterMark = mark
+		c.OnHighWater = onHigh
+		c.OnLowWater = onLow
+	}
+}
+
+// WithOnCallbackError sets OnCallbackError.
+func WithOnCallbackError(fn func(recovered interface{})) Option {
+	return func(c *Cache) { c.OnCallbackError = fn }
+}
+
+// WithSlidingTTL sets SlidingTTL.
+func WithSlidingTTL(sliding bool) Option {
+	return func(c *Cache) { c.SlidingTTL = sliding }
+}
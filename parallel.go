@@ -0,0 +1,78 @@
+// Copyright 2016 zxfonline@sina.com. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package lru
+
+import (
+	"runtime"
+	"sync"
+)
+
+// item is a key and its value, as copied out of the cache.
+type item struct {
+	key   Key
+	value interface{}
+}
+
+// ForeachParallel calls fn for every unexpired item of the cache from
+// workers goroutines, or GOMAXPROCS of them if workers is not positive,
+// and returns when all calls are done. The items are copied first, so fn
+// sees the cache as it was when ForeachParallel was called, in no
+// particular order. fn runs concurrently with itself and must not modify
+// the cache.
+func (c *Cache) ForeachParallel(workers int, fn func(Key, interface{})) {
+	parallel(c.items(), workers, fn)
+}
+
+// ForeachParallel calls fn for every unexpired item of the cache from
+// workers goroutines, see Cache.ForeachParallel. The items are copied
+// under the lock, which is released before fn is called, so fn may use
+// c, but it then sees the current cache rather than the copy.
+func (c *SyncCache) ForeachParallel(workers int, fn func(Key, interface{})) {
+	c.mu.Lock()
+	items := c.c.items()
+	c.mu.Unlock()
+	parallel(items, workers, fn)
+}
+
+// items returns the unexpired items of the cache from the oldest
+// to the newest, tombstones excluded.
+func (c *Cache) items() []item {
+	if c.Cache == nil {
+		return nil
+	}
+	items := make([]item, 0, c.Ll.Len())
+	for ele := c.Ll.Back(); ele != nil; ele = ele.Prev() {
+		if kv := ele.Value.(*entry); !kv.tombstone && !c.expiredNow(kv) {
+			items = append(items, item{kv.key, kv.value})
+		}
+	}
+	return items
+}
+
+// parallel calls fn for every item from workers goroutines.
+func parallel(items []item, workers int, fn func(Key, interface{})) {
+	if workers <= 0 {
+		workers = runtime.GOMAXPROCS(0)
+	}
+	if workers > len(items) {
+		workers = len(items)
+	}
+	next := make(chan item)
+	var wg sync.WaitGroup
+	wg.Add(workers)
+	for i := 0; i < workers; i++ {
+		go func() {
+			defer wg.Done()
+			for it := range next {
+				fn(it.key, it.value)
+			}
+		}()
+	}
+	for _, it := range items {
+		next <- it
+	}
+	close(next)
+	wg.Wait()
+}
@@ -0,0 +1,76 @@
+// Copyright 2016 zxfonline@sina.com. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package lru
+
+import (
+	"encoding/gob"
+	"io"
+	"time"
+)
+
+// record is the gob form of an entry.
+type record struct {
+	Key    Key
+	Value  interface{}
+	Expire time.Time
+	Added  time.Time
+}
+
+// Save writes the unexpired items of the cache to w with encoding/gob,
+// from the oldest to the newest, along with their expiry and the time
+// they were first added.
+// Keys and values are encoded as interfaces, so their concrete types
+// must be registered with gob.Register.
+func (c *Cache) Save(w io.Writer) error {
+	now := time.Now()
+	records := make([]record, 0, c.Len())
+	if c.Cache != nil {
+		for ele := c.Ll.Back(); ele != nil; ele = ele.Prev() {
+			if kv := ele.Value.(*entry); !kv.tombstone && !c.expired(kv, now) {
+				records = append(records, record{kv.key, kv.value, kv.expire, kv.added})
+			}
+		}
+	}
+	return gob.NewEncoder(w).Encode(records)
+}
+
+// Load replaces the content of the cache with the items read from r,
+// as written by Save, restoring their recency order, expiry and age.
+// The current items are removed first as if by Clear, so OnEvicted
+// is called for them. Items that expired in the meantime are skipped.
+func (c *Cache) Load(r io.Reader) error {
+	var records []record
+	if err := gob.NewDecoder(r).Decode(&records); err != nil {
+		return err
+	}
+	c.Clear()
+	now := time.Now()
+	for _, rec := range records {
+		if c.expired(&entry{expire: rec.Expire, added: rec.Added}, now) {
+			continue
+		}
+		c.add(rec.Key, rec.Value, rec.Expire)
+		if ele, ok := c.Cache[c.keyOf(rec.Key)]; ok && !rec.Added.IsZero() {
+			ele.Value.(*entry).added = rec.Added
+			c.schedule(ele)
+		}
+	}
+	return nil
+}
+
+// Save writes the unexpired items of the cache to w, see Cache.Save.
+func (c *SyncCache) Save(w io.Writer) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.c.Save(w)
+}
+
+// Load replaces the content of the cache with the items read from r,
+// see Cache.Load.
+func (c *SyncCache) Load(r io.Reader) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.c.Load(r)
+}
@@ -0,0 +1,200 @@
+// Copyright 2016 zxfonline@sina.com. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package lru
+
+import "container/list"
+
+// policy customizes how a Cache orders its items and which one it evicts.
+// New items are always pushed to the front of Ll.
+type policy interface {
+	// added is called when e is inserted.
+	added(c *Cache, e *list.Element)
+	// access is called when e is read or updated.
+	access(c *Cache, e *list.Element)
+	// victim returns the element to evict next, or nil. It must not
+	// return skip nor a pinned element.
+	victim(c *Cache, skip *list.Element) *list.Element
+	// clone returns a copy of the policy for a cloned cache.
+	clone() policy
+}
+
+// NewLFU creates a new Cache that evicts the least frequently used item,
+// breaking ties by evicting the least recently used one.
+// Eviction walks the whole cache and is O(n).
+func NewLFU(maxEntries int) *Cache {
+	c := New(maxEntries)
+	c.policy = lfu{}
+	return c
+}
+
+type lfu struct{}
+
+func (lfu) added(c *Cache, e *list.Element) {}
+
+func (p lfu) clone() policy { return p }
+
+func (lfu) access(c *Cache, e *list.Element) {
+	c.Ll.MoveToFront(e)
+}
+
+func (lfu) victim(c *Cache, skip *list.Element) *list.Element {
+	var min *list.Element
+	for e := c.Ll.Back(); e != nil; e = e.Prev() {
+		kv := e.Value.(*entry)
+		if e == skip || kv.pinned {
+			continue
+		}
+		if min == nil || kv.hits < min.Value.(*entry).hits {
+			min = e
+		}
+	}
+	return min
+}
+
+// NewFIFO creates a new Cache that evicts items in insertion order:
+// neither Get nor updating a key with Add moves it, so the first key
+// added is always the first evicted.
+func NewFIFO(maxEntries int) *Cache {
+	c := New(maxEntries)
+	c.policy = fifo{}
+	return c
+}
+
+type fifo struct{}
+
+func (fifo) added(c *Cache, e *list.Element) {}
+
+func (p fifo) clone() policy { return p }
+
+func (fifo) access(c *Cache, e *list.Element) {}
+
+func (fifo) victim(c *Cache, skip *list.Element) *list.Element {
+	return c.oldestUnpinned(skip)
+}
+
+// NewSampled creates a new Cache approximating LRU like Redis does: Get
+// only records an access time instead of reordering the list, and the
+// eviction picks the least recently used of sampleSize entries taken from
+// the map, whose iteration order is randomized.
+func NewSampled(maxEntries, sampleSize int) *Cache {
+	if sampleSize < 1 {
+		sampleSize = 1
+	}
+	c := New(maxEntries)
+	c.policy = &sampled{size: sampleSize}
+	return c
+}
+
+type sampled struct {
+	size  int
+	clock uint64
+}
+
+func (p *sampled) added(c *Cache, e *list.Element) {
+	p.clock++
+	e.Value.(*entry).tick = p.clock
+}
+
+func (p *sampled) clone() policy {
+	cp := *p
+	return &cp
+}
+
+func (p *sampled) access(c *Cache, e *list.Element) {
+	p.clock++
+	e.Value.(*entry).tick = p.clock
+}
+
+func (p *sampled) victim(c *Cache, skip *list.Element) *list.Element {
+	var min *list.Element
+	n := 0
+	for _, e := range c.Cache {
+		kv := e.Value.(*entry)
+		if e == skip || kv.pinned {
+			continue
+		}
+		if min == nil || kv.tick < min.Value.(*entry).tick {
+			min = e
+		}
+		if n++; n >= p.size {
+			break
+		}
+	}
+	if min == nil {
+		// Nothing evictable was sampled, fall back to the oldest.
+		return c.oldestUnpinned(skip)
+	}
+	return min
+}
+
+// NewLRUK creates a new Cache using the LRU-K policy, which evicts the
+// item whose K-th most recent access is the oldest instead of looking at
+// the last access only. An item accessed fewer than K times is evicted
+// first, the least recently used of them going first, so one-off accesses
+// can't push out items that are used again and again. Adding a key counts
+// as its first access. LRU-K is usually combined with a correlated
+// reference period, a short window in which repeated accesses count as
+// one; this implementation counts every access. Eviction walks the whole
+// cache and is O(n). A k below 1 is treated as 1, which is plain LRU.
+func NewLRUK(maxEntries, k int) *Cache {
+	if k < 1 {
+		k = 1
+	}
+	c := New(maxEntries)
+	c.policy = &lruK{k: k}
+	return c
+}
+
+type lruK struct {
+	k     int
+	clock uint64
+}
+
+func (p *lruK) clone() policy {
+	cp := *p
+	return &cp
+}
+
+func (p *lruK) record(e *list.Element) {
+	p.clock++
+	kv := e.Value.(*entry)
+	if len(kv.refs) == p.k {
+		copy(kv.refs, kv.refs[1:])
+		kv.refs = kv.refs[:p.k-1]
+	}
+	kv.refs = append(kv.refs, p.clock)
+}
+
+func (p *lruK) added(c *Cache, e *list.Element) {
+	p.record(e)
+}
+
+func (p *lruK) access(c *Cache, e *list.Element) {
+	p.record(e)
+	c.Ll.MoveToFront(e)
+}
+
+func (p *lruK) victim(c *Cache, skip *list.Element) *list.Element {
+	var min *list.Element
+	var minFull bool
+	var minRef uint64
+	for e := c.Ll.Back(); e != nil; e = e.Prev() {
+		kv := e.Value.(*entry)
+		if e == skip || kv.pinned {
+			continue
+		}
+		// A full history is compared by its K-th most recent access,
+		// a partial one by its last access.
+		full := len(kv.refs) == p.k
+		ref := kv.refs[len(kv.refs)-1]
+		if full {
+			ref = kv.refs[0]
+		}
+		if min == nil || (!full && minFull) || (full == minFull && ref < minRef) {
+			min, minFull, minRef = e, full, ref
+		}
+	}
+	return min
+}
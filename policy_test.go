@@ -0,0 +1,33 @@
+// Copyright 2016 zxfonline@sina.com. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package lru
+
+import "testing"
+
+func TestLRUKVictimOrder(t *testing.T) {
+	c := NewLRUK(3, 2)
+	c.Add("a", 1) // access 1
+	c.Add("b", 2) // 2
+	c.Add("c", 3) // 3
+	c.Get("b")    // 4
+	c.Get("a")    // 5
+	c.Get("a")    // 6
+	c.Get("b")    // 7
+	c.Get("c")    // 8
+
+	// Plain LRU would evict a, used last at 6; LRU-K evicts c, whose
+	// second most recent access, 3, is the oldest.
+	c.Add("d", 4)
+	if c.Contains("c") || !c.Contains("a") || !c.Contains("b") {
+		t.Fatalf("Keys() = %v, want c evicted", c.Keys())
+	}
+
+	// d was accessed once only, so it goes before a and b although it
+	// is the most recent of them.
+	c.Add("e", 5)
+	if c.Contains("d") || !c.Contains("a") || !c.Contains("b") {
+		t.Fatalf("Keys() = %v, want d evicted", c.Keys())
+	}
+}
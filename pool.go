@@ -0,0 +1,37 @@
+// Copyright 2016 zxfonline@sina.com. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package lru
+
+import "sync"
+
+// entryPool recycles the entries of the caches created with
+// WithEntryPool.
+var entryPool = sync.Pool{New: func() interface{} { return new(entry) }}
+
+// WithEntryPool makes the cache recycle the storage of the entries it
+// removes for the keys it adds later, which lowers the garbage produced
+// by a cache with a high turnover. An entry is only recycled after every
+// callback about its removal has run, and the key and value callbacks
+// receive stay valid, but an Iterator, or an element of Ll, kept past the
+// removal of its item may then see another item.
+func WithEntryPool(on bool) Option {
+	return func(c *Cache) { c.pooled = on }
+}
+
+// newEntry returns a zero entry, recycled if the cache uses the pool.
+func (c *Cache) newEntry() *entry {
+	if !c.pooled {
+		return new(entry)
+	}
+	return entryPool.Get().(*entry)
+}
+
+// release recycles e, which left the cache, if the cache uses the pool.
+func (c *Cache) release(e *entry) {
+	if c.pooled {
+		*e = entry{}
+		entryPool.Put(e)
+	}
+}
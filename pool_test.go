@@ -0,0 +1,57 @@
+// Copyright 2016 zxfonline@sina.com. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package lru
+
+import (
+	"runtime"
+	"sync"
+	"testing"
+	"time"
+)
+
+const churnEntries = 1024
+
+func benchmarkAddChurn(b *testing.B, opts ...Option) {
+	c := NewWithOptions(append([]Option{WithMaxEntries(churnEntries)}, opts...)...)
+	for i := 0; i < churnEntries; i++ {
+		c.Add(i, i)
+	}
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		c.Add(churnEntries+i, i)
+	}
+}
+
+func BenchmarkAddChurn(b *testing.B) {
+	b.Run("plain", func(b *testing.B) { benchmarkAddChurn(b) })
+	b.Run("pooled", func(b *testing.B) { benchmarkAddChurn(b, WithEntryPool(true)) })
+}
+
+// TestEntryPoolSeparateCaches runs pooled caches with TTLs in parallel;
+// it catches, under -race, a cache reading a recycled entry that another
+// cache reuses.
+func TestEntryPoolSeparateCaches(t *testing.T) {
+	var wg sync.WaitGroup
+	for g := 0; g < 4; g++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			c := NewWithOptions(WithMaxEntries(16), WithEntryPool(true), WithDefaultTTL(time.Minute))
+			for i := 0; i < 2000; i++ {
+				c.Add(i, i)
+				if i%16 == 0 {
+					// Hand the released entries to the other caches.
+					for j := i - 15; j <= i; j++ {
+						c.Remove(j)
+					}
+					runtime.GC()
+				}
+				c.RemoveExpired()
+			}
+		}()
+	}
+	wg.Wait()
+}
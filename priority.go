@@ -0,0 +1,65 @@
+// Copyright 2016 zxfonline@sina.com. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package lru
+
+import "container/list"
+
+// AddWithPriority is like Add but gives the value a priority. When the
+// cache evicts an item for MaxEntries or MaxBytes, it picks the oldest
+// item of the lowest priority, so that an item of higher priority is only
+// evicted when no item of lower priority is left; Add gives priority 0,
+// and adding the key again with Add resets it, while Update, Merge and
+// SyncCache.CompareAndSwap keep it. The cache never grows
+// beyond its limits because of priorities: when every other item has a
+// higher priority than the one added, one of them is evicted, as the item
+// just added is never the one evicted to make room for itself.
+// While any item has a non-zero priority, eviction walks the cache and
+// is O(n). Priorities are ignored by the eviction policies of NewLFU,
+// NewFIFO, NewSampled and NewLRUK.
+func (c *Cache) AddWithPriority(key Key, value interface{}, priority int) {
+	if !c.admits(key) {
+		return
+	}
+	if ele := c.set(key, value, c.expireAt(c.DefaultTTL), c.costOf(key, value)); ele != nil {
+		c.setPriority(ele.Value.(*entry), priority)
+	}
+	c.shrink()
+}
+
+// setPriority sets the priority of e, keeping count of the entries with
+// a non-zero one.
+func (c *Cache) setPriority(e *entry, priority int) {
+	switch {
+	case e.priority == 0 && priority != 0:
+		c.prioritized++
+	case e.priority != 0 && priority == 0:
+		c.prioritized--
+	}
+	e.priority = priority
+}
+
+// lowestPriority returns the element nearest to the back of Ll with the
+// lowest priority that is neither pinned nor skip, or nil.
+func (c *Cache) lowestPriority(skip *list.Element) *list.Element {
+	var victim *list.Element
+	for e := c.Ll.Back(); e != nil; e = e.Prev() {
+		kv := e.Value.(*entry)
+		if e == skip || kv.pinned {
+			continue
+		}
+		if victim == nil || kv.priority < victim.Value.(*entry).priority {
+			victim = e
+		}
+	}
+	return victim
+}
+
+// AddWithPriority is like Add but gives the value a priority,
+// see Cache.AddWithPriority.
+func (c *SyncCache) AddWithPriority(key Key, value interface{}, priority int) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.c.AddWithPriority(key, value, priority)
+}
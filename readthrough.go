@@ -0,0 +1,59 @@
+// Copyright 2016 zxfonline@sina.com. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package lru
+
+import "sync"
+
+// ReadThrough wraps a Cache to load the values of missing keys with a
+// load function. It is safe for concurrent access through its own
+// methods, and concurrent misses for the same key share a single load.
+type ReadThrough struct {
+	mu    sync.Mutex
+	c     *Cache
+	load  func(key Key) (interface{}, error)
+	loads group
+}
+
+// NewReadThrough creates a new ReadThrough over c loading missing keys
+// with load.
+func NewReadThrough(c *Cache, load func(key Key) (interface{}, error)) *ReadThrough {
+	return &ReadThrough{c: c, load: load}
+}
+
+// Get looks up a key's value from the cache, or calls load and adds its
+// result if the key is missing. The lock is not held while load runs.
+// Errors are returned to every waiting caller but are not cached, and a
+// panic of load is propagated to every waiting caller.
+func (r *ReadThrough) Get(key Key) (interface{}, error) {
+	if value, ok := r.locked(r.c.Get, key); ok {
+		return value, nil
+	}
+	return r.loads.do(r.c.keyOf(key), func() (interface{}, error) {
+		if value, ok := r.locked(r.c.Peek, key); ok {
+			return value, nil
+		}
+		value, err := r.load(key)
+		if err != nil {
+			return nil, err
+		}
+		r.mu.Lock()
+		defer r.mu.Unlock()
+		r.c.Add(key, value)
+		return value, nil
+	})
+}
+
+// locked calls get with the lock held.
+func (r *ReadThrough) locked(get func(Key) (interface{}, bool), key Key) (interface{}, bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return get(key)
+}
+
+// Cache returns the wrapped cache. It is not guarded by the lock of r,
+// so it must not be used while other goroutines call Get.
+func (r *ReadThrough) Cache() *Cache {
+	return r.c
+}
@@ -0,0 +1,45 @@
+// Copyright 2016 zxfonline@sina.com. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package lru
+
+import (
+	"testing"
+	"time"
+)
+
+func TestReadThroughPanicReachesWaiters(t *testing.T) {
+	started := make(chan struct{})
+	release := make(chan struct{})
+	panicking := true
+	r := NewReadThrough(New(0), func(key Key) (interface{}, error) {
+		if !panicking {
+			return 1, nil
+		}
+		close(started)
+		<-release
+		panic("boom")
+	})
+	go func() {
+		defer func() { recover() }()
+		r.Get("a")
+	}()
+	<-started
+	waited := make(chan interface{})
+	go func() {
+		defer func() { waited <- recover() }()
+		v, err := r.Get("a")
+		t.Errorf("Get = %v, %v; want a panic", v, err)
+	}()
+	// Give the second call time to join the first one.
+	time.Sleep(20 * time.Millisecond)
+	close(release)
+	if rec := <-waited; rec != "boom" {
+		t.Fatalf("waiter recovered %v, want boom", rec)
+	}
+	panicking = false
+	if v, err := r.Get("a"); v != 1 || err != nil {
+		t.Fatalf("Get after the panic = %v, %v; want 1, <nil>", v, err)
+	}
+}
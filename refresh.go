@@ -0,0 +1,31 @@
+// Copyright 2016 zxfonline@sina.com. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package lru
+
+import "time"
+
+// refreshKey is the group key of a refresh, so that it never joins a
+// GetOrLoad call for the same key.
+type refreshKey struct{ key Key }
+
+// refreshDue reports whether e should be reloaded for RefreshAhead.
+func (c *Cache) refreshDue(e *entry) bool {
+	if c.RefreshAhead <= 0 || c.Loader == nil || e.ttl <= 0 || e.tombstone {
+		return false
+	}
+	left := time.Until(e.expire)
+	return float64(left) <= (1-c.RefreshAhead)*float64(e.ttl)
+}
+
+// refresh reloads key with loader in the background, unless a refresh
+// of key, normalized by KeyFunc, is already running, and stores the
+// result for ttl.
+func (c *SyncCache) refresh(key Key, ttl time.Duration, loader func(Key) (interface{}, bool)) {
+	c.loads.start(refreshKey{c.c.keyOf(key)}, func() {
+		if value, ok := loader(key); ok {
+			c.AddWithTTL(key, value, ttl)
+		}
+	})
+}
@@ -0,0 +1,46 @@
+// Copyright 2016 zxfonline@sina.com. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package lru
+
+import (
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestRefreshGroupsByNormalizedKey(t *testing.T) {
+	var loads int32
+	release := make(chan struct{})
+	c := NewSyncWithOptions(
+		WithKeyFunc(func(key Key) Key { return strings.ToLower(key.(string)) }),
+		WithLoader(func(key Key) (interface{}, bool) {
+			atomic.AddInt32(&loads, 1)
+			<-release
+			return 2, true
+		}),
+		WithRefreshAhead(0.5),
+	)
+	c.AddWithTTL("a", 1, time.Hour)
+	// Make the refresh due without waiting for the TTL to elapse.
+	c.c.Cache["a"].Value.(*entry).expire = time.Now().Add(time.Minute)
+	for _, key := range []string{"a", "A"} {
+		if v, ok := c.Get(key); !ok || v != 1 {
+			t.Fatalf("Get(%q) = %v, %v; want 1, true", key, v, ok)
+		}
+	}
+	close(release)
+	for deadline := time.Now().Add(time.Second); ; time.Sleep(time.Millisecond) {
+		c.loads.mu.Lock()
+		running := len(c.loads.m)
+		c.loads.mu.Unlock()
+		if running == 0 || time.Now().After(deadline) {
+			break
+		}
+	}
+	if n := atomic.LoadInt32(&loads); n != 1 {
+		t.Fatalf("loader ran %d times, want 1", n)
+	}
+}
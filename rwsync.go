@@ -0,0 +1,95 @@
+// Copyright 2016 zxfonline@sina.com. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package lru
+
+import (
+	"sync"
+	"time"
+)
+
+// RWCache is an LRU cache that is safe for concurrent access and lets
+// lookups that don't modify the cache run in parallel under a read lock.
+// Peek, Contains, Len and Keys only take the read lock; Get takes the
+// write lock since it updates recent-ness, unless the cache was created
+// without promotion on Get.
+type RWCache struct {
+	mu      sync.RWMutex
+	c       *Cache
+	promote bool
+}
+
+// NewRW creates a new RWCache.
+// If promoteOnGet is false, Get behaves like Peek: it runs under the read
+// lock but neither updates recent-ness nor counts hits and misses, so the
+// eviction order follows insertion and updates with Add.
+// If maxEntries is zero, the cache has no limit.
+func NewRW(maxEntries int, promoteOnGet bool) *RWCache {
+	return &RWCache{c: New(maxEntries), promote: promoteOnGet}
+}
+
+// Add adds a value to the cache.
+func (c *RWCache) Add(key Key, value interface{}) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.c.Add(key, value)
+}
+
+// AddWithTTL adds a value to the cache that expires after ttl,
+// see Cache.AddWithTTL.
+func (c *RWCache) AddWithTTL(key Key, value interface{}, ttl time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.c.AddWithTTL(key, value, ttl)
+}
+
+// Get looks up a key's value from the cache.
+func (c *RWCache) Get(key Key) (value interface{}, ok bool) {
+	if !c.promote {
+		return c.Peek(key)
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.c.Get(key)
+}
+
+// Peek looks up a key's value from the cache without updating its
+// recent-ness. Expired items are reported as missing but left in place.
+func (c *RWCache) Peek(key Key) (value interface{}, ok bool) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	if ele, hit := c.c.peek(key); hit {
+		return ele.Value.(*entry).value, true
+	}
+	return
+}
+
+// Contains reports whether key is in the cache without updating
+// its recent-ness.
+func (c *RWCache) Contains(key Key) bool {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.c.Contains(key)
+}
+
+// Remove removes the provided key from the cache.
+func (c *RWCache) Remove(key Key) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.c.Remove(key)
+}
+
+// Len returns the number of items in the cache.
+func (c *RWCache) Len() int {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.c.Len()
+}
+
+// Keys returns the keys in the cache from the oldest to the newest.
+func (c *RWCache) Keys() []Key {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.c.Keys()
+}
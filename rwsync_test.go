@@ -0,0 +1,43 @@
+// Copyright 2016 zxfonline@sina.com. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package lru
+
+import "testing"
+
+// reader is the read side shared by SyncCache and RWCache.
+type reader interface {
+	Add(key Key, value interface{})
+	Get(key Key) (interface{}, bool)
+	Peek(key Key) (interface{}, bool)
+}
+
+func benchmarkParallelRead(b *testing.B, c reader, read func(reader, Key) (interface{}, bool)) {
+	for i := 0; i < benchEntries; i++ {
+		c.Add(i, i)
+	}
+	b.ReportAllocs()
+	b.ResetTimer()
+	b.RunParallel(func(pb *testing.PB) {
+		i := 0
+		for pb.Next() {
+			read(c, i%benchEntries)
+			i++
+		}
+	})
+}
+
+func readGet(c reader, key Key) (interface{}, bool)  { return c.Get(key) }
+func readPeek(c reader, key Key) (interface{}, bool) { return c.Peek(key) }
+
+func BenchmarkParallelPeek(b *testing.B) {
+	b.Run("SyncCache", func(b *testing.B) { benchmarkParallelRead(b, NewSync(benchEntries), readPeek) })
+	b.Run("RWCache", func(b *testing.B) { benchmarkParallelRead(b, NewRW(benchEntries, false), readPeek) })
+}
+
+func BenchmarkParallelGet(b *testing.B) {
+	b.Run("SyncCache", func(b *testing.B) { benchmarkParallelRead(b, NewSync(benchEntries), readGet) })
+	b.Run("RWCache", func(b *testing.B) { benchmarkParallelRead(b, NewRW(benchEntries, false), readGet) })
+	b.Run("RWCachePromote", func(b *testing.B) { benchmarkParallelRead(b, NewRW(benchEntries, true), readGet) })
+}
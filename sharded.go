@@ -0,0 +1,101 @@
+// Copyright 2016 zxfonline@sina.com. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package lru
+
+import (
+	"fmt"
+	"hash/fnv"
+)
+
+// ShardedCache spreads keys over several SyncCache shards, each with its
+// own lock, to reduce lock contention. It is safe for concurrent access.
+type ShardedCache struct {
+	// Hash optionally specifies the function used to pick the shard
+	// of a key. It defaults to FNV-1a over the fmt formatted key and
+	// must be set before the cache is used.
+	Hash func(Key) uint64
+
+	shards []*SyncCache
+}
+
+// NewSharded creates a new ShardedCache with the given number of shards,
+// splitting maxEntries evenly between them. Every shard holds at least one
+// entry, so the total limit may exceed maxEntries when it is smaller
+// than shards. If maxEntries is zero, the shards have no limit.
+func NewSharded(shards, maxEntries int) *ShardedCache {
+	if shards <= 0 {
+		shards = 1
+	}
+	c := &ShardedCache{shards: make([]*SyncCache, shards)}
+	for i := range c.shards {
+		n := maxEntries / shards
+		if i < maxEntries%shards {
+			n++
+		}
+		if maxEntries != 0 && n == 0 {
+			n = 1
+		}
+		c.shards[i] = NewSync(n)
+	}
+	return c
+}
+
+func defaultHash(key Key) uint64 {
+	h := fnv.New64a()
+	if s, ok := key.(string); ok {
+		h.Write([]byte(s))
+	} else {
+		fmt.Fprint(h, key)
+	}
+	return h.Sum64()
+}
+
+func (c *ShardedCache) shard(key Key) *SyncCache {
+	hash := c.Hash
+	if hash == nil {
+		hash = defaultHash
+	}
+	return c.shards[hash(key)%uint64(len(c.shards))]
+}
+
+// Add adds a value to the cache.
+func (c *ShardedCache) Add(key Key, value interface{}) {
+	c.shard(key).Add(key, value)
+}
+
+// Get looks up a key's value from the cache.
+func (c *ShardedCache) Get(key Key) (value interface{}, ok bool) {
+	return c.shard(key).Get(key)
+}
+
+// Remove removes the provided key from the cache.
+func (c *ShardedCache) Remove(key Key) {
+	c.shard(key).Remove(key)
+}
+
+// Len returns the number of items in all the shards.
+func (c *ShardedCache) Len() int {
+	n := 0
+	for _, s := range c.shards {
+		n += s.Len()
+	}
+	return n
+}
+
+// Foreach walks every shard in turn from its oldest item, see
+// Cache.Foreach. Each shard is locked while it is walked, so fn must
+// not call back into c. Returning true from fn stops the whole walk.
+func (c *ShardedCache) Foreach(fn func(Key, interface{}) bool) {
+	for _, s := range c.shards {
+		stop := false
+		s.Foreach(func(key Key, value interface{}) bool {
+			stop = fn(key, value)
+			return stop
+		})
+		if stop {
+			return
+		}
+	}
+}
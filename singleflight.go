@@ -0,0 +1,103 @@
+// Copyright 2016 zxfonline@sina.com. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package lru
+
+import (
+	"errors"
+	"sync"
+)
+
+// errGoexit is returned to the callers waiting on a function that called
+// runtime.Goexit.
+var errGoexit = errors.New("lru: load function called runtime.Goexit")
+
+// call is an in-flight or completed group.do call.
+type call struct {
+	wg  sync.WaitGroup
+	val interface{}
+	err error
+
+	// panicked is set if fn did not return, with the value it panicked
+	// with in recovered.
+	panicked  bool
+	recovered interface{}
+}
+
+// group coalesces concurrent calls for the same key into one.
+type group struct {
+	mu sync.Mutex
+	m  map[interface{}]*call
+}
+
+// do executes fn, making sure only one execution is in-flight for a
+// given key at a time. Duplicate callers wait for the original call
+// to complete and receive the same results. If fn panics, the panic is
+// propagated to every caller.
+func (g *group) do(key Key, fn func() (interface{}, error)) (interface{}, error) {
+	g.mu.Lock()
+	if g.m == nil {
+		g.m = make(map[interface{}]*call)
+	}
+	if c, ok := g.m[key]; ok {
+		g.mu.Unlock()
+		c.wg.Wait()
+		if c.panicked && c.recovered != nil {
+			panic(c.recovered)
+		}
+		return c.val, c.err
+	}
+	c := new(call)
+	c.wg.Add(1)
+	g.m[key] = c
+	g.mu.Unlock()
+
+	defer func() {
+		if c.panicked {
+			// recover returns nil when fn called runtime.Goexit, which
+			// keeps unwinding the caller.
+			if c.recovered = recover(); c.recovered == nil {
+				c.err = errGoexit
+			}
+		}
+		c.wg.Done()
+		g.mu.Lock()
+		delete(g.m, key)
+		g.mu.Unlock()
+		if c.recovered != nil {
+			panic(c.recovered)
+		}
+	}()
+	c.panicked = true
+	c.val, c.err = fn()
+	c.panicked = false
+	return c.val, c.err
+}
+
+// start is like do but runs fn in a new goroutine and returns at once.
+// It does nothing if a call for key is already in-flight.
+func (g *group) start(key Key, fn func()) {
+	g.mu.Lock()
+	if g.m == nil {
+		g.m = make(map[interface{}]*call)
+	}
+	if _, ok := g.m[key]; ok {
+		g.mu.Unlock()
+		return
+	}
+	c := new(call)
+	c.wg.Add(1)
+	g.m[key] = c
+	g.mu.Unlock()
+
+	go func() {
+		defer func() {
+			c.wg.Done()
+			g.mu.Lock()
+			delete(g.m, key)
+			g.mu.Unlock()
+		}()
+		fn()
+	}()
+}
@@ -0,0 +1,43 @@
+// Copyright 2016 zxfonline@sina.com. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package lru
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestGetOrLoadPanicReachesWaiters(t *testing.T) {
+	c := NewSync(0)
+	started := make(chan struct{})
+	release := make(chan struct{})
+	go func() {
+		defer func() { recover() }()
+		c.GetOrLoad("a", func() (interface{}, error) {
+			close(started)
+			<-release
+			panic("boom")
+		})
+	}()
+	<-started
+	waited := make(chan interface{})
+	go func() {
+		defer func() { waited <- recover() }()
+		v, err := c.GetOrLoad("a", func() (interface{}, error) {
+			return nil, errors.New("loader not shared")
+		})
+		t.Errorf("GetOrLoad = %v, %v; want a panic", v, err)
+	}()
+	// Give the second call time to join the first one.
+	time.Sleep(20 * time.Millisecond)
+	close(release)
+	if r := <-waited; r != "boom" {
+		t.Fatalf("waiter recovered %v, want boom", r)
+	}
+	if _, ok := c.Get("a"); ok {
+		t.Fatal("a was cached")
+	}
+}
@@ -0,0 +1,38 @@
+// Copyright 2016 zxfonline@sina.com. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package lru
+
+// A Sizer is a value that knows its approximate size in bytes.
+type Sizer interface {
+	Size() int64
+}
+
+// ApproxBytes returns a best-effort estimate of the memory held by the
+// values of the cache: the sum of Size for values implementing Sizer and
+// DefaultEntrySize for the others. Keys, the map and the list are not
+// accounted for. It walks the whole cache and is only meant for
+// observability; see MaxBytes and Cost to bound the cache by size.
+func (c *Cache) ApproxBytes() int64 {
+	if c.Cache == nil {
+		return 0
+	}
+	var n int64
+	for ele := c.Ll.Back(); ele != nil; ele = ele.Prev() {
+		if s, ok := ele.Value.(*entry).value.(Sizer); ok {
+			n += s.Size()
+		} else {
+			n += c.DefaultEntrySize
+		}
+	}
+	return n
+}
+
+// ApproxBytes returns a best-effort estimate of the memory held by the
+// values of the cache, see Cache.ApproxBytes.
+func (c *SyncCache) ApproxBytes() int64 {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.c.ApproxBytes()
+}
@@ -0,0 +1,83 @@
+// Copyright 2016 zxfonline@sina.com. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package lru
+
+// SLRUCache is a segmented LRU cache. New keys enter a probationary
+// segment; a hit there promotes the entry to a protected segment, whose
+// least recently used entry is demoted back to probation when it
+// overflows. Only probationary entries are evicted while there are any,
+// so entries hit at least twice survive a burst of new keys.
+// It is not safe for concurrent access.
+type SLRUCache struct {
+	maxEntries    int
+	protectedSize int
+
+	probation *Cache
+	protected *Cache
+}
+
+// NewSLRU creates a new SLRUCache holding up to maxEntries entries, of
+// which protectedRatio can be in the protected segment. It panics if
+// maxEntries is not positive or protectedRatio is out of [0, 1].
+func NewSLRU(maxEntries int, protectedRatio float64) *SLRUCache {
+	if maxEntries <= 0 {
+		panic("lru: SLRUCache size must be positive")
+	}
+	if protectedRatio < 0 || protectedRatio > 1 {
+		panic("lru: SLRUCache protected ratio must be in [0, 1]")
+	}
+	return &SLRUCache{
+		maxEntries:    maxEntries,
+		protectedSize: int(float64(maxEntries) * protectedRatio),
+		probation:     New(0),
+		protected:     New(0),
+	}
+}
+
+// Add adds a value to the cache. A new key enters the probationary
+// segment, evicting the oldest probationary entry if the cache is full.
+func (c *SLRUCache) Add(key Key, value interface{}) {
+	if c.protected.Contains(key) {
+		c.protected.Add(key, value)
+		return
+	}
+	c.probation.Add(key, value)
+	for c.Len() > c.maxEntries {
+		if c.probation.Len() > 1 {
+			c.probation.RemoveOldest()
+		} else {
+			c.protected.RemoveOldest()
+		}
+	}
+}
+
+// Get looks up a key's value from the cache, promoting a probationary
+// entry to the protected segment.
+func (c *SLRUCache) Get(key Key) (value interface{}, ok bool) {
+	if value, ok = c.protected.Get(key); ok {
+		return value, true
+	}
+	if value, ok = c.probation.Peek(key); !ok {
+		return nil, false
+	}
+	c.probation.Remove(key)
+	c.protected.Add(key, value)
+	for c.protected.Len() > c.protectedSize {
+		k, v, _ := c.protected.PopOldest()
+		c.probation.Add(k, v)
+	}
+	return value, true
+}
+
+// Remove removes the provided key from the cache.
+func (c *SLRUCache) Remove(key Key) {
+	c.protected.Remove(key)
+	c.probation.Remove(key)
+}
+
+// Len returns the number of items in the cache.
+func (c *SLRUCache) Len() int {
+	return c.probation.Len() + c.protected.Len()
+}
@@ -0,0 +1,37 @@
+// Copyright 2016 zxfonline@sina.com. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package lru
+
+import "testing"
+
+func TestSLRUPromotionAndDemotion(t *testing.T) {
+	c := NewSLRU(4, 0.5) // the protected segment holds 2 entries
+	c.Add("a", 1)
+	c.Add("b", 2)
+	c.Add("c", 3)
+	c.Get("a")
+	c.Get("b")
+	if !c.protected.Contains("a") || !c.protected.Contains("b") {
+		t.Fatal("a and b not promoted to the protected segment")
+	}
+	c.Get("c") // overflows the protected segment, demoting a
+	if !c.probation.Contains("a") || !c.protected.Contains("c") {
+		t.Fatal("a not demoted to probation when c was promoted")
+	}
+
+	c.Add("d", 4)
+	c.Add("e", 5) // full: evicts the oldest probationary entry
+	if c.Len() != 4 {
+		t.Fatalf("Len() = %d, want 4", c.Len())
+	}
+	if _, ok := c.probation.Peek("a"); ok {
+		t.Fatal("a survived in probation, want it evicted first")
+	}
+	for _, key := range []string{"b", "c"} {
+		if !c.protected.Contains(key) {
+			t.Errorf("protected %s was evicted", key)
+		}
+	}
+}
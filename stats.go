@@ -0,0 +1,50 @@
+// Copyright 2016 zxfonline@sina.com. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package lru
+
+// Stats holds the counters of a cache.
+type Stats struct {
+	Hits      uint64 // Get calls that found the key
+	Misses    uint64 // Get calls that did not find the key
+	Evictions uint64 // items removed by RemoveOldest or to fit the limits
+	Bytes     int64  // current total cost of the items, see Cache.Cost
+}
+
+// HitRatio returns Hits/(Hits+Misses), or zero if there was no lookup.
+func (s Stats) HitRatio() float64 {
+	total := s.Hits + s.Misses
+	if total == 0 {
+		return 0
+	}
+	return float64(s.Hits) / float64(total)
+}
+
+// Stats returns the counters of the cache.
+// Explicit removals with Remove are not counted as evictions.
+func (c *Cache) Stats() Stats {
+	s := c.stats
+	s.Bytes = c.bytes
+	return s
+}
+
+// ResetStats sets all counters of the cache back to zero.
+// Bytes is not a counter and is left untouched.
+func (c *Cache) ResetStats() {
+	c.stats = Stats{}
+}
+
+// Stats returns the counters of the cache.
+func (c *SyncCache) Stats() Stats {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.c.Stats()
+}
+
+// ResetStats sets all counters of the cache back to zero.
+func (c *SyncCache) ResetStats() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.c.ResetStats()
+}
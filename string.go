@@ -0,0 +1,44 @@
+// Copyright 2016 zxfonline@sina.com. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package lru
+
+import (
+	"fmt"
+	"strings"
+)
+
+// maxStringEntries is the number of items String prints before
+// truncating the output.
+const maxStringEntries = 100
+
+// String renders the cache for debugging, from the oldest to the newest
+// item, e.g. "lru.Cache(len=2/max=10): [k1=v1 k2=v2]". Only the first
+// 100 items are printed. It does not update recent-ness.
+func (c *Cache) String() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "lru.Cache(len=%d/max=%d): [", c.Len(), c.MaxEntries)
+	n := 0
+	c.Foreach(func(key Key, value interface{}) bool {
+		if n == maxStringEntries {
+			b.WriteString(" ...")
+			return true
+		}
+		if n > 0 {
+			b.WriteByte(' ')
+		}
+		fmt.Fprintf(&b, "%v=%v", key, value)
+		n++
+		return false
+	})
+	b.WriteByte(']')
+	return b.String()
+}
+
+// String renders the cache for debugging, see Cache.String.
+func (c *SyncCache) String() string {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.c.String()
+}
@@ -0,0 +1,505 @@
+// Copyright 2016 zxfonline@sina.com. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package lru
+
+import (
+	"sync"
+	"time"
+)
+
+// SyncCache is an LRU cache that is safe for concurrent access.
+// Every operation is guarded by a single mutex around a Cache, released
+// with defer so that a panicking callback never leaves it locked.
+type SyncCache struct {
+	mu    sync.Mutex
+	c     *Cache
+	loads group
+}
+
+// NewSync creates a new SyncCache.
+// If maxEntries is zero, the cache has no limit and it's assumed
+// that eviction is done by the caller.
+func NewSync(maxEntries int) *SyncCache {
+	return &SyncCache{c: New(maxEntries)}
+}
+
+// Add adds a value to the cache.
+func (c *SyncCache) Add(key Key, value interface{}) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.c.Add(key, value)
+}
+
+// AddIfAbsent adds a value to the cache only if key is missing,
+// see Cache.AddIfAbsent.
+func (c *SyncCache) AddIfAbsent(key Key, value interface{}) (actual interface{}, loaded bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.c.AddIfAbsent(key, value)
+}
+
+// Swap is like Add but also returns the value key had, see Cache.Swap.
+func (c *SyncCache) Swap(key Key, value interface{}) (previous interface{}, had bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.c.Swap(key, value)
+}
+
+// Get looks up a key's value from the cache.
+// It starts a background refresh of the value if RefreshAhead is due.
+func (c *SyncCache) Get(key Key) (value interface{}, ok bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	ele, hit := c.c.get(key)
+	if !hit {
+		return nil, false
+	}
+	kv := ele.Value.(*entry)
+	if c.c.refreshDue(kv) {
+		c.refresh(key, kv.ttl, c.c.Loader)
+	}
+	return kv.value, true
+}
+
+// GetOrAdd looks up a key's value from the cache, or calls loader and
+// adds its result if the key is missing, see Cache.GetOrAdd.
+// The lock is held across loader, so only one loader runs at a time;
+// loader must not call back into c.
+func (c *SyncCache) GetOrAdd(key Key, loader func() interface{}) (value interface{}, loaded bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.c.GetOrAdd(key, loader)
+}
+
+// GetOrLoad looks up a key's value from the cache, or calls loader and
+// adds its result if the key is missing. Concurrent misses for the same
+// key share a single loader call and all receive its result.
+// The lock is not held while loader runs. Errors are returned to every
+// waiting caller but are not cached, and a panic of loader is propagated
+// to every waiting caller.
+func (c *SyncCache) GetOrLoad(key Key, loader func() (interface{}, error)) (interface{}, error) {
+	if value, ok := c.Get(key); ok {
+		return value, nil
+	}
+	return c.loads.do(c.c.keyOf(key), func() (interface{}, error) {
+		c.mu.Lock()
+		value, ok := c.c.Peek(key)
+		c.mu.Unlock()
+		if ok {
+			return value, nil
+		}
+		value, err := loader()
+		if err != nil {
+			return nil, err
+		}
+		c.Add(key, value)
+		return value, nil
+	})
+}
+
+// GetQuiet is like Get but is not counted in Stats.
+func (c *SyncCache) GetQuiet(key Key) (value interface{}, ok bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.c.GetQuiet(key)
+}
+
+// Peek looks up a key's value from the cache without updating
+// its recent-ness.
+func (c *SyncCache) Peek(key Key) (value interface{}, ok bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.c.Peek(key)
+}
+
+// Contains reports whether key is in the cache without updating
+// its recent-ness.
+func (c *SyncCache) Contains(key Key) bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.c.Contains(key)
+}
+
+// Remove removes the provided key from the cache.
+func (c *SyncCache) Remove(key Key) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.c.Remove(key)
+}
+
+// RemoveOldest removes the oldest item from the cache.
+func (c *SyncCache) RemoveOldest() Key {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.c.RemoveOldest()
+}
+
+// GetOldest returns the oldest item of the cache, see Cache.GetOldest.
+func (c *SyncCache) GetOldest() (key Key, value interface{}, ok bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.c.GetOldest()
+}
+
+// GetNewest returns the most recently used item of the cache,
+// see Cache.GetNewest.
+func (c *SyncCache) GetNewest() (key Key, value interface{}, ok bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.c.GetNewest()
+}
+
+// Len returns the number of items in the cache.
+func (c *SyncCache) Len() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.c.Len()
+}
+
+// Keys returns the keys in the cache from the oldest to the newest.
+func (c *SyncCache) Keys() []Key {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.c.Keys()
+}
+
+// OldestN returns up to n keys from the oldest, see Cache.OldestN.
+func (c *SyncCache) OldestN(n int) []Key {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.c.OldestN(n)
+}
+
+// NewestN returns up to n keys from the newest, see Cache.NewestN.
+func (c *SyncCache) NewestN(n int) []Key {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.c.NewestN(n)
+}
+
+// Cap returns the maximum number of items of the cache.
+func (c *SyncCache) Cap() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.c.Cap()
+}
+
+// Full reports whether the cache holds its maximum number of items.
+func (c *SyncCache) Full() bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.c.Full()
+}
+
+// Foreach walks the cache from the oldest item, see Cache.Foreach.
+// fn runs with the cache lock held, so it must not call back into c.
+func (c *SyncCache) Foreach(fn func(Key, interface{}) bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.c.Foreach(fn)
+}
+
+// ForeachNewest walks the cache from the newest item, see
+// Cache.ForeachNewest.
+// fn runs with the cache lock held, so it must not call back into c.
+func (c *SyncCache) ForeachNewest(fn func(Key, interface{}) bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.c.ForeachNewest(fn)
+}
+
+// ForeachOrdered walks the cache in the given direction, see
+// Cache.ForeachOrdered.
+// fn runs with the cache lock held, so it must not call back into c.
+func (c *SyncCache) ForeachOrdered(newestFirst bool, fn func(Key, interface{}) bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.c.ForeachOrdered(newestFirst, fn)
+}
+
+// RemoveForeach walks the cache from the oldest item, see Cache.RemoveForeach.
+// fn runs with the cache lock held, so it must not call back into c.
+func (c *SyncCache) RemoveForeach(fn func(Key, interface{}) (bool, bool)) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.c.RemoveForeach(fn)
+}
+
+// Resize changes MaxEntries and evicts the oldest items until the cache
+// fits in the new limit, see Cache.Resize.
+func (c *SyncCache) Resize(maxEntries int) []EvictedEntry {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.c.Resize(maxEntries)
+}
+
+// AddWithTTL adds a value to the cache that expires after ttl,
+// see Cache.AddWithTTL.
+func (c *SyncCache) AddWithTTL(key Key, value interface{}, ttl time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.c.AddWithTTL(key, value, ttl)
+}
+
+// Snapshot returns a copy of the unexpired items of the cache as a map.
+func (c *SyncCache) Snapshot() map[Key]interface{} {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.c.Snapshot()
+}
+
+// AddBatch adds values[i] under keys[i] for every i, see Cache.AddBatch.
+func (c *SyncCache) AddBatch(keys []Key, values []interface{}) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.c.AddBatch(keys, values)
+}
+
+// Touch marks key as the most recently used item, see Cache.Touch.
+func (c *SyncCache) Touch(key Key) bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.c.Touch(key)
+}
+
+// TouchRefresh marks key as the most recently used item and makes it
+// expire after ttl from now, see Cache.TouchRefresh.
+func (c *SyncCache) TouchRefresh(key Key, ttl time.Duration) bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.c.TouchRefresh(key, ttl)
+}
+
+// RemoveFunc removes every item for which match returns true,
+// see Cache.RemoveFunc. match runs with the cache lock held.
+func (c *SyncCache) RemoveFunc(match func(key Key, value interface{}) bool) int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.c.RemoveFunc(match)
+}
+
+// Update replaces the value of key with the result of fn in one locked
+// operation, see Cache.Update. fn must not call back into c.
+func (c *SyncCache) Update(key Key, fn func(old interface{}, ok bool) (new interface{}, store bool)) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.c.Update(key, fn)
+}
+
+// PopOldest removes the oldest item from the cache and returns it.
+func (c *SyncCache) PopOldest() (key Key, value interface{}, ok bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.c.PopOldest()
+}
+
+// PopOldestFunc removes the oldest matching item from the cache and
+// returns it, see Cache.PopOldestFunc. match runs with the cache lock held.
+func (c *SyncCache) PopOldestFunc(match func(key Key, value interface{}) bool) (key Key, value interface{}, ok bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.c.PopOldestFunc(match)
+}
+
+// PopNewest removes the newest item from the cache and returns it.
+func (c *SyncCache) PopNewest() (key Key, value interface{}, ok bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.c.PopNewest()
+}
+
+// GetWithExpire is like Get but also returns when the value expires,
+// see Cache.GetWithExpire.
+func (c *SyncCache) GetWithExpire(key Key) (value interface{}, expireAt time.Time, ok bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.c.GetWithExpire(key)
+}
+
+// GetAndRefreshTTL is like Get but also makes the value expire after ttl
+// from now, see Cache.GetAndRefreshTTL.
+func (c *SyncCache) GetAndRefreshTTL(key Key, ttl time.Duration) (value interface{}, ok bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.c.GetAndRefreshTTL(key, ttl)
+}
+
+// ExpireAt makes key expire at t, see Cache.ExpireAt.
+func (c *SyncCache) ExpireAt(key Key, t time.Time) bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.c.ExpireAt(key, t)
+}
+
+// ExpireFunc makes every matching item expire now, see Cache.ExpireFunc.
+// match runs with the cache lock held.
+func (c *SyncCache) ExpireFunc(match func(key Key, value interface{}) bool) int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.c.ExpireFunc(match)
+}
+
+// ExtendTTL pushes the expiry of key back by d, see Cache.ExtendTTL.
+func (c *SyncCache) ExtendTTL(key Key, d time.Duration) bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.c.ExtendTTL(key, d)
+}
+
+// RemoveOldestN removes up to n of the oldest items from the cache,
+// see Cache.RemoveOldestN.
+func (c *SyncCache) RemoveOldestN(n int) []Key {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.c.RemoveOldestN(n)
+}
+
+// PruneTo evicts the oldest items until the cache holds at most size
+// of them, see Cache.PruneTo.
+func (c *SyncCache) PruneTo(size int) (evicted int) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.c.PruneTo(size)
+}
+
+// CountFunc returns the number of items for which match returns true,
+// see Cache.CountFunc. match runs with the cache lock held.
+func (c *SyncCache) CountFunc(match func(key Key, value interface{}) bool) int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.c.CountFunc(match)
+}
+
+// AddWithEviction is like Add but also returns the key of the item it
+// evicted to make room, see Cache.AddWithEviction.
+func (c *SyncCache) AddWithEviction(key Key, value interface{}) (evictedKey Key, evicted bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.c.AddWithEviction(key, value)
+}
+
+// GetMulti looks up the values of keys under a single lock,
+// see Cache.GetMulti.
+func (c *SyncCache) GetMulti(keys []Key) map[Key]interface{} {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.c.GetMulti(keys)
+}
+
+// RemoveMulti removes the provided keys under a single lock,
+// see Cache.RemoveMulti.
+func (c *SyncCache) RemoveMulti(keys []Key) int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.c.RemoveMulti(keys)
+}
+
+// ExpiredKeys returns the keys of the expired items still in the cache,
+// see Cache.ExpiredKeys.
+func (c *SyncCache) ExpiredKeys() []Key {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.c.ExpiredKeys()
+}
+
+// ActiveLen returns the number of live items, see Cache.ActiveLen.
+func (c *SyncCache) ActiveLen() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.c.ActiveLen()
+}
+
+// AddWeighted adds a value to the cache with an explicit cost,
+// see Cache.AddWeighted.
+func (c *SyncCache) AddWeighted(key Key, value interface{}, cost int64) bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.c.AddWeighted(key, value, cost)
+}
+
+// Clear removes all items from the cache, see Cache.Clear.
+func (c *SyncCache) Clear() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.c.Clear()
+}
+
+// Reset removes all items from the cache but keeps its memory,
+// see Cache.Reset.
+func (c *SyncCache) Reset() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.c.Reset()
+}
+
+// Pin exempts key from capacity eviction until Unpin is called,
+// see Cache.Pin.
+func (c *SyncCache) Pin(key Key) bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.c.Pin(key)
+}
+
+// Unpin makes key subject to capacity eviction again, see Cache.Unpin.
+func (c *SyncCache) Unpin(key Key) bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.c.Unpin(key)
+}
+
+// Compact rebuilds the map of the cache to fit its current length,
+// see Cache.Compact.
+func (c *SyncCache) Compact() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.c.Compact()
+}
+
+// AddChecked is like Add but reports whether the value was stored,
+// see Cache.AddChecked.
+func (c *SyncCache) AddChecked(key Key, value interface{}) bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.c.AddChecked(key, value)
+}
+
+// SetOnEvicted replaces the OnEvicted callback of the cache under the
+// lock, so that concurrent evictions never see it half set.
+func (c *SyncCache) SetOnEvicted(fn func(key Key, value interface{})) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.c.OnEvicted = fn
+}
+
+// SetOnAdded replaces the OnAdded callback of the cache under the lock.
+func (c *SyncCache) SetOnAdded(fn func(key Key, value interface{})) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.c.OnAdded = fn
+}
+
+// CompareAndSwap replaces the value of key with new only if its current
+// value is old, compared with ==, and marks it as the most recently used
+// item, keeping its expiry, its dirty mark from AddDirty and its
+// priority from AddWithPriority. It reports whether the value was swapped and
+// returns false if key is not in the cache. The values must be of
+// comparable types, or == panics.
+func (c *SyncCache) CompareAndSwap(key Key, old, new interface{}) bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	ele, hit := c.c.lookup(key)
+	if !hit {
+		return false
+	}
+	kv := ele.Value.(*entry)
+	if kv.value != old {
+		return false
+	}
+	if c.c.replace(kv, new, kv.expire, c.c.costOf(key, new)) == nil {
+		return false
+	}
+	c.c.shrink()
+	return true
+}
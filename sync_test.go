@@ -0,0 +1,68 @@
+// Copyright 2016 zxfonline@sina.com. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package lru
+
+import "testing"
+
+func TestCompareAndSwapKeepsDirtyAndPriority(t *testing.T) {
+	c := NewSync(0)
+	c.AddDirty("a", 1)
+	c.AddWithPriority("b", 1, 5)
+	if !c.CompareAndSwap("a", 1, 2) || !c.CompareAndSwap("b", 1, 2) {
+		t.Fatal("CompareAndSwap failed")
+	}
+	var flushed map[Key]interface{}
+	c.Flush(func(m map[Key]interface{}) error { flushed = m; return nil })
+	if flushed["a"] != 2 {
+		t.Fatalf("Flush got %v, want a=2", flushed)
+	}
+	if p := c.c.Cache["b"].Value.(*entry).priority; p != 5 {
+		t.Fatalf("priority of b = %d, want 5", p)
+	}
+}
+
+func TestSyncCacheClear(t *testing.T) {
+	var evicted int
+	c := NewSync(0)
+	c.c.OnEvicted = func(key Key, value interface{}) { evicted++ }
+	c.Add("a", 1)
+	c.Add("b", 2)
+	c.Clear()
+	if c.Len() != 0 || evicted != 2 {
+		t.Fatalf("Len() = %d, %d evicted; want 0, 2", c.Len(), evicted)
+	}
+}
+
+func TestSyncCacheOldestNewest(t *testing.T) {
+	c := NewSync(0)
+	c.Add("a", 1)
+	c.Add("b", 2)
+	if k, v, ok := c.GetOldest(); k != "a" || v != 1 || !ok {
+		t.Errorf("GetOldest() = %v, %v, %v; want a, 1, true", k, v, ok)
+	}
+	if k, v, ok := c.GetNewest(); k != "b" || v != 2 || !ok {
+		t.Errorf("GetNewest() = %v, %v, %v; want b, 2, true", k, v, ok)
+	}
+}
+
+func TestSyncCachePin(t *testing.T) {
+	c := NewSync(2)
+	c.Add("a", 1)
+	if !c.Pin("a") {
+		t.Fatal("Pin(a) = false")
+	}
+	c.Add("b", 2)
+	c.Add("c", 3)
+	if !c.Contains("a") || c.Contains("b") {
+		t.Fatalf("Keys() = %v, want pinned a kept and b evicted", c.Keys())
+	}
+	if !c.Unpin("a") || c.Pin("b") {
+		t.Fatal("Unpin(a) = false or Pin(b) = true")
+	}
+	c.Add("d", 4)
+	if c.Contains("a") {
+		t.Fatalf("Keys() = %v, want unpinned a evicted", c.Keys())
+	}
+}
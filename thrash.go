@@ -0,0 +1,36 @@
+// Copyright 2016 zxfonline@sina.com. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package lru
+
+// defaultThrashWindow sizes the thrash sketch of a cache without
+// MaxEntries.
+const defaultThrashWindow = 1024
+
+// evictedForCapacity records that key was evicted to make room, for
+// OnThrash.
+func (c *Cache) evictedForCapacity(key Key) {
+	if c.OnThrash == nil || c.ThrashThreshold <= 0 {
+		return
+	}
+	if c.thrash == nil {
+		size := c.MaxEntries
+		if size <= 0 {
+			size = defaultThrashWindow
+		}
+		c.thrash = newSketch(size)
+	}
+	c.thrash.add(key)
+}
+
+// readded calls OnThrash if key, being added again, was evicted for
+// capacity at least ThrashThreshold times recently.
+func (c *Cache) readded(key Key) {
+	if c.OnThrash == nil || c.thrash == nil {
+		return
+	}
+	if n := int(c.thrash.estimate(key)); n >= c.ThrashThreshold {
+		c.callback(func() { c.OnThrash(key, n) })
+	}
+}
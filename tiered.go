@@ -0,0 +1,61 @@
+// Copyright 2016 zxfonline@sina.com. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package lru
+
+// TieredCache is a two-level cache. New keys enter a small first tier;
+// entries it evicts for capacity are demoted to a larger second tier
+// instead of being lost, and a hit in the second tier promotes the entry
+// back to the first one. Entries evicted from the second tier are gone.
+// It is not safe for concurrent access.
+type TieredCache struct {
+	l1 *Cache
+	l2 *Cache
+}
+
+// NewTiered creates a new TieredCache whose tiers hold up to l1Max and
+// l2Max entries. It panics if either is not positive.
+func NewTiered(l1Max, l2Max int) *TieredCache {
+	if l1Max <= 0 || l2Max <= 0 {
+		panic("lru: TieredCache sizes must be positive")
+	}
+	c := &TieredCache{l1: New(l1Max), l2: New(l2Max)}
+	c.l1.OnEvictedReason = func(key Key, value interface{}, reason EvictReason) {
+		if reason == ReasonCapacity {
+			c.l2.Add(key, value)
+		}
+	}
+	return c
+}
+
+// Add adds a value to the first tier.
+func (c *TieredCache) Add(key Key, value interface{}) {
+	c.l2.Remove(key)
+	c.l1.Add(key, value)
+}
+
+// Get looks up a key's value from the first tier, then from the second
+// one, promoting the entry to the first tier if it is found there.
+func (c *TieredCache) Get(key Key) (value interface{}, ok bool) {
+	if value, ok = c.l1.Get(key); ok {
+		return value, true
+	}
+	if value, ok = c.l2.Peek(key); !ok {
+		return nil, false
+	}
+	c.l2.Remove(key)
+	c.l1.Add(key, value)
+	return value, true
+}
+
+// Remove removes the provided key from both tiers.
+func (c *TieredCache) Remove(key Key) {
+	c.l1.Remove(key)
+	c.l2.Remove(key)
+}
+
+// Len returns the number of items in both tiers.
+func (c *TieredCache) Len() int {
+	return c.l1.Len() + c.l2.Len()
+}
@@ -0,0 +1,106 @@
+// Copyright 2016 zxfonline@sina.com. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package lru
+
+// sketchDepth is the number of rows of the count-min sketch.
+const sketchDepth = 4
+
+// NewTinyLFU creates a new Cache with a TinyLFU admission filter in front
+// of its LRU eviction. The cache keeps an approximate count of recent
+// accesses to every key, hit or miss, in a count-min sketch. When adding
+// a new key to a full cache, the key is only admitted if it was accessed
+// more often than the item that would be evicted for it; otherwise Add
+// leaves the cache unchanged. The counts are halved at regular intervals
+// so that the filter follows a shifting workload. The filter applies to
+// Add, AddWithTTL and AddWithEviction.
+func NewTinyLFU(maxEntries int) *Cache {
+	c := New(maxEntries)
+	if maxEntries > 0 {
+		c.admit = newSketch(maxEntries)
+	}
+	return c
+}
+
+// admits reports whether key may be added to the cache, evicting the
+// current victim, and records the access to key.
+func (c *Cache) admits(key Key) bool {
+	if c.admit == nil {
+		return true
+	}
+	key = c.keyOf(key)
+	c.admit.add(key)
+	if !c.Full() || c.Contains(key) {
+		return true
+	}
+	victim := c.victim(nil)
+	return victim == nil || c.admit.estimate(key) > c.admit.estimate(victim.Value.(*entry).key)
+}
+
+// sketch is a count-min sketch of key frequencies with saturating
+// counters that are halved every resetAt additions.
+type sketch struct {
+	rows    [sketchDepth][]uint8
+	mask    uint64
+	added   int
+	resetAt int
+}
+
+func newSketch(size int) *sketch {
+	width := 64
+	for width < 4*size {
+		width <<= 1
+	}
+	s := &sketch{mask: uint64(width - 1), resetAt: 10 * size}
+	for i := range s.rows {
+		s.rows[i] = make([]uint8, width)
+	}
+	return s
+}
+
+func (s *sketch) clone() *sketch {
+	cp := *s
+	for i := range cp.rows {
+		cp.rows[i] = append([]uint8(nil), s.rows[i]...)
+	}
+	return &cp
+}
+
+// index returns the counter of key in row i, using double hashing.
+func (s *sketch) index(h uint64, i int) uint64 {
+	return (h + uint64(i)*(h>>32|1)) & s.mask
+}
+
+func (s *sketch) add(key Key) {
+	h := defaultHash(key)
+	for i := range s.rows {
+		if j := s.index(h, i); s.rows[i][j] < 255 {
+			s.rows[i][j]++
+		}
+	}
+	if s.added++; s.added >= s.resetAt {
+		s.age()
+	}
+}
+
+func (s *sketch) estimate(key Key) uint8 {
+	h := defaultHash(key)
+	min := uint8(255)
+	for i := range s.rows {
+		if v := s.rows[i][s.index(h, i)]; v < min {
+			min = v
+		}
+	}
+	return min
+}
+
+// age halves every counter.
+func (s *sketch) age() {
+	for i := range s.rows {
+		for j := range s.rows[i] {
+			s.rows[i][j] >>= 1
+		}
+	}
+	s.added /= 2
+}
@@ -0,0 +1,30 @@
+// Copyright 2016 zxfonline@sina.com. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package lru
+
+import "testing"
+
+func TestTinyLFURejectsColdKey(t *testing.T) {
+	c := NewTinyLFU(2)
+	c.Add("a", 1)
+	c.Add("b", 2)
+	for i := 0; i < 3; i++ {
+		c.Get("a")
+		c.Get("b")
+	}
+	c.Add("c", 3) // seen once, colder than the victim a
+	if c.Contains("c") || !c.Contains("a") || !c.Contains("b") {
+		t.Fatalf("cold c was admitted, Keys() = %v", c.Keys())
+	}
+
+	// Misses count too: a key looked up often enough is admitted.
+	for i := 0; i < 5; i++ {
+		c.Get("d")
+	}
+	c.Add("d", 4)
+	if !c.Contains("d") || c.Len() != 2 {
+		t.Fatalf("hot d was rejected, Keys() = %v", c.Keys())
+	}
+}
@@ -0,0 +1,67 @@
+// Copyright 2016 zxfonline@sina.com. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package lru
+
+import "time"
+
+// LookupResult tells what Lookup knows about a key.
+type LookupResult int
+
+const (
+	Unknown     LookupResult = iota // the key is not cached
+	Found                           // the key is cached with a value
+	KnownAbsent                     // the key is cached as a tombstone
+)
+
+// AddTombstone records that key is known not to exist, replacing its
+// value if any. Get and the other lookups report a tombstone as a miss,
+// while Lookup reports it as KnownAbsent. The tombstone expires after ttl,
+// or is only evicted by capacity if ttl is zero or negative, and counts
+// toward MaxEntries like any other item.
+func (c *Cache) AddTombstone(key Key, ttl time.Duration) {
+	c.addTombstone(key, expireAt(ttl))
+}
+
+func (c *Cache) addTombstone(key Key, expire time.Time) {
+	if ele := c.set(key, nil, expire, 0); ele != nil {
+		ele.Value.(*entry).tombstone = true
+	}
+	c.shrink()
+}
+
+// Lookup is like Get but tells a key known to be absent, stored with
+// AddTombstone, apart from a key the cache knows nothing about.
+// Tombstones update their recent-ness like values do and are counted
+// as misses in Stats.
+func (c *Cache) Lookup(key Key) (value interface{}, result LookupResult) {
+	ele, hit := c.find(key)
+	if !hit {
+		c.stats.Misses++
+		return nil, Unknown
+	}
+	c.promote(ele)
+	kv := ele.Value.(*entry)
+	if kv.tombstone {
+		c.stats.Misses++
+		return nil, KnownAbsent
+	}
+	c.stats.Hits++
+	return kv.value, Found
+}
+
+// AddTombstone records that key is known not to exist,
+// see Cache.AddTombstone.
+func (c *SyncCache) AddTombstone(key Key, ttl time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.c.AddTombstone(key, ttl)
+}
+
+// Lookup is like Get but tells known absent keys apart, see Cache.Lookup.
+func (c *SyncCache) Lookup(key Key) (value interface{}, result LookupResult) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.c.Lookup(key)
+}
@@ -0,0 +1,233 @@
+// Copyright 2016 zxfonline@sina.com. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package lru
+
+import (
+	"container/list"
+	"math/rand"
+	"time"
+)
+
+// AddWithTTL adds a value to the cache that expires after ttl.
+// An expired item is treated as a miss and removed, calling OnEvicted,
+// the next time it is looked up.
+// A zero or negative ttl means the value never expires, like Add.
+// The actual expiry is spread by TTLJitter.
+func (c *Cache) AddWithTTL(key Key, value interface{}, ttl time.Duration) {
+	c.add(key, value, c.expireAt(ttl))
+}
+
+// GetWithExpire is like Get but also returns when the value expires.
+// expireAt is the zero time for a value that never expires.
+func (c *Cache) GetWithExpire(key Key) (value interface{}, expireAt time.Time, ok bool) {
+	if ele, hit := c.get(key); hit {
+		kv := ele.Value.(*entry)
+		return kv.value, kv.expire, true
+	}
+	return
+}
+
+// TouchRefresh is like Touch but also makes key expire after ttl from now.
+// A zero or negative ttl means the value never expires.
+func (c *Cache) TouchRefresh(key Key, ttl time.Duration) bool {
+	ele, hit := c.lookup(key)
+	if !hit {
+		return false
+	}
+	c.promote(ele)
+	kv := ele.Value.(*entry)
+	kv.expire = expireAt(ttl)
+	kv.ttl = ttl
+	c.schedule(ele)
+	return true
+}
+
+// GetAndRefreshTTL is like Get but also makes the value expire after ttl
+// from now, whatever TTL it was added with. A zero or negative ttl means
+// the value never expires.
+func (c *Cache) GetAndRefreshTTL(key Key, ttl time.Duration) (value interface{}, ok bool) {
+	ele, hit := c.get(key)
+	if !hit {
+		return nil, false
+	}
+	kv := ele.Value.(*entry)
+	kv.expire = expireAt(ttl)
+	kv.ttl = ttl
+	c.schedule(ele)
+	return kv.value, true
+}
+
+// ExpireAt makes key expire at t, or never if t is the zero time,
+// without updating its recent-ness. A key that never expires also stops
+// being renewed by SlidingTTL and refreshed by RefreshAhead. It reports
+// whether key was found.
+func (c *Cache) ExpireAt(key Key, t time.Time) bool {
+	ele, hit := c.lookup(key)
+	if hit {
+		kv := ele.Value.(*entry)
+		kv.expire = t
+		if t.IsZero() {
+			kv.ttl = 0
+		}
+		c.schedule(ele)
+	}
+	return hit
+}
+
+// ExtendTTL pushes the expiry of key back by d, without updating its
+// recent-ness. A value that never expired so far expires after d from now.
+// It reports whether key was found.
+func (c *Cache) ExtendTTL(key Key, d time.Duration) bool {
+	ele, hit := c.lookup(key)
+	if !hit {
+		return false
+	}
+	kv := ele.Value.(*entry)
+	if kv.expire.IsZero() {
+		kv.expire = time.Now().Add(d)
+	} else {
+		kv.expire = kv.expire.Add(d)
+	}
+	c.schedule(ele)
+	return true
+}
+
+// ExpireFunc makes every unexpired item for which match returns true
+// expire now, whether it had a TTL or not, and returns their number. The
+// items are left in place: they are reported as misses from then on and
+// removed, calling OnEvicted, when they are next looked up or swept by
+// RemoveExpired, like any expired item. Tombstones are not matched.
+// match must not modify the cache.
+func (c *Cache) ExpireFunc(match func(key Key, value interface{}) bool) int {
+	if c.Cache == nil {
+		return 0
+	}
+	now := time.Now()
+	n := 0
+	for ele := c.Ll.Back(); ele != nil; ele = ele.Prev() {
+		kv := ele.Value.(*entry)
+		if kv.tombstone || c.expired(kv, now) || !match(kv.key, kv.value) {
+			continue
+		}
+		kv.expire = now.Add(-1)
+		c.schedule(ele)
+		n++
+	}
+	return n
+}
+
+// expireAt returns the expiry of an entry added now with ttl.
+func expireAt(ttl time.Duration) time.Time {
+	if ttl <= 0 {
+		return time.Time{}
+	}
+	return time.Now().Add(ttl)
+}
+
+// expireAt is like the expireAt function but applies TTLJitter to ttl.
+func (c *Cache) expireAt(ttl time.Duration) time.Time {
+	if c.TTLJitter > 0 && ttl > 0 {
+		ttl += time.Duration((rand.Float64()*2 - 1) * c.TTLJitter * float64(ttl))
+		if ttl <= 0 {
+			ttl = 1
+		}
+	}
+	return expireAt(ttl)
+}
+
+// expired reports whether the entry is past its expiry or MaxAge at now.
+func (c *Cache) expired(e *entry, now time.Time) bool {
+	if !e.expire.IsZero() && now.After(e.expire) {
+		return true
+	}
+	return c.MaxAge > 0 && !e.added.IsZero() && now.Sub(e.added) > c.MaxAge
+}
+
+// expiredNow is like expired at the current time, but does not read the
+// clock for an entry that cannot expire.
+func (c *Cache) expiredNow(e *entry) bool {
+	if e.expire.IsZero() && c.MaxAge <= 0 {
+		return false
+	}
+	return c.expired(e, time.Now())
+}
+
+// peek returns the element of key unless it has expired or is a
+// tombstone, without modifying the cache.
+func (c *Cache) peek(key Key) (*list.Element, bool) {
+	if c.Cache == nil {
+		return nil, false
+	}
+	ele, hit := c.Cache[c.keyOf(key)]
+	if !hit {
+		return nil, false
+	}
+	kv := ele.Value.(*entry)
+	if kv.tombstone || c.expiredNow(kv) {
+		return nil, false
+	}
+	return ele, true
+}
+
+// lookup returns the element of key, removing it if it has expired.
+// Tombstones are reported as missing.
+func (c *Cache) lookup(key Key) (*list.Element, bool) {
+	ele, hit := c.find(key)
+	if !hit || ele.Value.(*entry).tombstone {
+		return nil, false
+	}
+	return ele, true
+}
+
+// find returns the element of key, tombstones included, removing it
+// if it has expired.
+func (c *Cache) find(key Key) (*list.Element, bool) {
+	if c.Cache == nil {
+		return nil, false
+	}
+	ele, hit := c.Cache[c.keyOf(key)]
+	if !hit {
+		return nil, false
+	}
+	if c.expiredNow(ele.Value.(*entry)) {
+		c.removeElement(ele, ReasonExpired)
+		return nil, false
+	}
+	return ele, true
+}
+
+// ExpiredKeys returns the keys of the expired items still in the cache,
+// from the oldest to the newest, without removing them or calling any
+// callback. RemoveExpired removes them.
+func (c *Cache) ExpiredKeys() []Key {
+	keys := []Key{}
+	if c.Cache == nil {
+		return keys
+	}
+	now := time.Now()
+	for ele := c.Ll.Back(); ele != nil; ele = ele.Prev() {
+		if kv := ele.Value.(*entry); c.expired(kv, now) {
+			keys = append(keys, kv.key)
+		}
+	}
+	return keys
+}
+
+// ActiveLen returns the number of items that are neither expired nor
+// tombstones, without removing anything. Unlike Len it walks the whole
+// cache and is O(n).
+func (c *Cache) ActiveLen() int {
+	if c.Cache == nil {
+		return 0
+	}
+	now := time.Now()
+	n := 0
+	for ele := c.Ll.Back(); ele != nil; ele = ele.Prev() {
+		if kv := ele.Value.(*entry); !kv.tombstone && !c.expired(kv, now) {
+			n++
+		}
+	}
+	return n
+}
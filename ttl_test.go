@@ -0,0 +1,98 @@
+// Copyright 2016 zxfonline@sina.com. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package lru
+
+import (
+	"bytes"
+	"testing"
+	"time"
+)
+
+func TestMaxAgeSetAfterAdd(t *testing.T) {
+	c := New(0)
+	c.Add("a", 1)
+	c.MaxAge = time.Hour
+	if _, ok := c.Get("a"); !ok {
+		t.Fatal("item added before MaxAge was set expired at once")
+	}
+}
+
+func TestLoadIntoMaxAgeCache(t *testing.T) {
+	src := New(0)
+	src.Add("a", 1)
+	var buf bytes.Buffer
+	if err := src.Save(&buf); err != nil {
+		t.Fatal(err)
+	}
+	dst := NewWithOptions(WithMaxAge(time.Hour))
+	if err := dst.Load(&buf); err != nil {
+		t.Fatal(err)
+	}
+	if dst.Len() != 1 {
+		t.Fatalf("Len() = %d, want 1", dst.Len())
+	}
+}
+
+func TestUpdateNewKeyUsesDefaultTTL(t *testing.T) {
+	c := NewWithOptions(WithDefaultTTL(time.Hour))
+	c.Update("a", func(old interface{}, ok bool) (interface{}, bool) { return 1, true })
+	if _, expire, ok := c.GetWithExpire("a"); !ok || expire.IsZero() {
+		t.Fatalf("GetWithExpire(a) = %v, %v, want a DefaultTTL expiry", expire, ok)
+	}
+}
+
+func TestUpdateKeepsSlidingTTL(t *testing.T) {
+	c := NewWithOptions(WithSlidingTTL(true), WithRefreshAhead(0.5),
+		WithLoader(func(key Key) (interface{}, bool) { return 2, true }))
+	c.AddWithTTL("a", 1, time.Hour)
+	c.ExpireAt("a", time.Now().Add(time.Minute))
+	c.Update("a", func(old interface{}, ok bool) (interface{}, bool) { return 2, true })
+	_, expire, ok := c.GetWithExpire("a")
+	if !ok || time.Until(expire) < 59*time.Minute {
+		t.Fatalf("expiry after a sliding read is in %v, want about an hour", time.Until(expire))
+	}
+}
+
+func TestAddWeightedJitter(t *testing.T) {
+	c := NewWithOptions(WithDefaultTTL(time.Hour), WithTTLJitter(0.5))
+	var min, max time.Time
+	for i := 0; i < 20; i++ {
+		c.AddWeighted(i, i, 1)
+		_, expire, _ := c.GetWithExpire(i)
+		if min.IsZero() || expire.Before(min) {
+			min = expire
+		}
+		if expire.After(max) {
+			max = expire
+		}
+	}
+	if max.Sub(min) < time.Minute {
+		t.Fatalf("AddWeighted expiries spread over %v, want jitter", max.Sub(min))
+	}
+}
+
+func TestExpireAtZeroClearsTTL(t *testing.T) {
+	c := NewWithOptions(WithSlidingTTL(true), WithRefreshAhead(0.5),
+		WithLoader(func(key Key) (interface{}, bool) { return 2, true }))
+	c.AddWithTTL("a", 1, time.Hour)
+	c.ExpireAt("a", time.Time{})
+	c.Get("a")
+	if e := c.Cache["a"].Value.(*entry); !e.expire.IsZero() || e.ttl != 0 {
+		t.Fatalf("expire = %v, ttl = %v after Get; want no expiry", e.expire, e.ttl)
+	}
+	if c.refreshDue(c.Cache["a"].Value.(*entry)) {
+		t.Fatal("refresh due for an item that never expires")
+	}
+}
+
+func TestRemoveExpiredMaxAgeSetAfterAdd(t *testing.T) {
+	c := New(0)
+	c.Add("a", 1)
+	c.MaxAge = time.Millisecond
+	time.Sleep(5 * time.Millisecond)
+	if n := c.RemoveExpired(); n != 1 || c.Len() != 0 {
+		t.Fatalf("RemoveExpired() = %d, Len() = %d; want 1, 0", n, c.Len())
+	}
+}
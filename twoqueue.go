@@ -0,0 +1,123 @@
+// Copyright 2016 zxfonline@sina.com. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package lru
+
+const (
+	// Default2QRecentRatio is the share of a TwoQueueCache given to
+	// entries seen only once.
+	Default2QRecentRatio = 0.25
+
+	// Default2QGhostEntries is the number of evicted recent keys a
+	// TwoQueueCache remembers, relative to its size.
+	Default2QGhostEntries = 0.50
+)
+
+// TwoQueueCache is a cache implementing the 2Q algorithm, which resists
+// scans better than plain LRU. New keys enter a FIFO queue of recently
+// added entries (A1in); keys evicted from it are remembered without their
+// value in a ghost queue (A1out). A key accessed again while in A1in, or
+// added again while in A1out, moves to the main LRU (Am), so a single
+// pass over many keys can't push out the frequently used ones.
+// It is not safe for concurrent access.
+type TwoQueueCache struct {
+	size       int
+	recentSize int
+
+	recent      *Cache // A1in
+	frequent    *Cache // Am
+	recentEvict *Cache // A1out, keys only
+}
+
+// NewTwoQueue creates a new TwoQueueCache holding up to size entries,
+// with the default queue ratios.
+func NewTwoQueue(size int) *TwoQueueCache {
+	return NewTwoQueueParams(size, Default2QRecentRatio, Default2QGhostEntries)
+}
+
+// NewTwoQueueParams creates a new TwoQueueCache holding up to size entries.
+// recentRatio is the share of size for entries seen once, and ghostRatio
+// the number of remembered evicted keys relative to size.
+// It panics if size is not positive or a ratio is out of [0, 1].
+func NewTwoQueueParams(size int, recentRatio, ghostRatio float64) *TwoQueueCache {
+	if size <= 0 {
+		panic("lru: TwoQueueCache size must be positive")
+	}
+	if recentRatio < 0 || recentRatio > 1 || ghostRatio < 0 || ghostRatio > 1 {
+		panic("lru: TwoQueueCache ratios must be in [0, 1]")
+	}
+	evictSize := int(float64(size) * ghostRatio)
+	if evictSize < 1 {
+		evictSize = 1
+	}
+	return &TwoQueueCache{
+		size:        size,
+		recentSize:  int(float64(size) * recentRatio),
+		recent:      New(0),
+		frequent:    New(0),
+		recentEvict: New(evictSize),
+	}
+}
+
+// Add adds a value to the cache.
+func (c *TwoQueueCache) Add(key Key, value interface{}) {
+	if c.frequent.Contains(key) {
+		c.frequent.Add(key, value)
+		return
+	}
+	if c.recent.Contains(key) {
+		c.recent.Remove(key)
+		c.frequent.Add(key, value)
+		return
+	}
+	if c.recentEvict.Contains(key) {
+		c.ensureSpace(true)
+		c.recentEvict.Remove(key)
+		c.frequent.Add(key, value)
+		return
+	}
+	c.ensureSpace(false)
+	c.recent.Add(key, value)
+}
+
+// ensureSpace evicts an entry if the cache is full. recentEvict tells
+// whether the entry about to be added comes from the ghost queue.
+func (c *TwoQueueCache) ensureSpace(recentEvict bool) {
+	if c.recent.Len()+c.frequent.Len() < c.size {
+		return
+	}
+	n := c.recent.Len()
+	if n > 0 && (n > c.recentSize || (n == c.recentSize && !recentEvict)) {
+		key, _, _ := c.recent.PopOldest()
+		c.recentEvict.Add(key, nil)
+		return
+	}
+	c.frequent.RemoveOldest()
+}
+
+// Get looks up a key's value from the cache. A hit on an entry seen
+// only once moves it to the main LRU.
+func (c *TwoQueueCache) Get(key Key) (value interface{}, ok bool) {
+	if value, ok = c.frequent.Get(key); ok {
+		return value, true
+	}
+	if value, ok = c.recent.Peek(key); ok {
+		c.recent.Remove(key)
+		c.frequent.Add(key, value)
+		return value, true
+	}
+	return nil, false
+}
+
+// Remove removes the provided key from the cache.
+func (c *TwoQueueCache) Remove(key Key) {
+	c.frequent.Remove(key)
+	c.recent.Remove(key)
+	c.recentEvict.Remove(key)
+}
+
+// Len returns the number of items in the cache.
+func (c *TwoQueueCache) Len() int {
+	return c.recent.Len() + c.frequent.Len()
+}
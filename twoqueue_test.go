@@ -0,0 +1,43 @@
+// Copyright 2016 zxfonline@sina.com. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package lru
+
+import (
+	"strconv"
+	"testing"
+)
+
+func TestTwoQueuePromotion(t *testing.T) {
+	c := NewTwoQueue(4) // A1in holds 1 entry, A1out 2 keys
+	c.Add("a", 1)
+	c.Get("a") // a hit in A1in moves a to Am
+	if !c.frequent.Contains("a") {
+		t.Fatal("a not promoted to Am by Get")
+	}
+	c.Add("b", 2)
+	c.Add("c", 3)
+	c.Add("d", 4)
+	c.Add("e", 5) // A1in is over its share, b goes to A1out
+	if c.recent.Contains("b") || !c.recentEvict.Contains("b") {
+		t.Fatal("b not moved from A1in to A1out")
+	}
+	c.Add("b", 2) // a key added again from A1out goes to Am
+	if !c.frequent.Contains("b") || c.recentEvict.Contains("b") {
+		t.Fatal("b not promoted from A1out to Am")
+	}
+
+	// A scan of new keys only cycles through A1in.
+	for i := 0; i < 10; i++ {
+		c.Add(strconv.Itoa(i), i)
+	}
+	for _, key := range []string{"a", "b"} {
+		if _, ok := c.Get(key); !ok {
+			t.Errorf("%s was evicted by a scan", key)
+		}
+	}
+	if c.Len() != 4 {
+		t.Fatalf("Len() = %d, want 4", c.Len())
+	}
+}
@@ -0,0 +1,138 @@
+// Copyright 2016 zxfonline@sina.com. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package lru
+
+import "container/list"
+
+// TypedCache is an LRU cache with typed keys and values, so lookups need
+// no type assertion. It is not safe for concurrent access.
+// It is the fast path for hot caches with string or int keys: keys are
+// never boxed into interfaces, so Get does not allocate, and once the
+// cache is full Add reuses the storage of the evicted item for the new
+// one, so it does not allocate either.
+type TypedCache[K comparable, V any] struct {
+	// MaxEntries is the maximum number of cache entries before
+	// an item is evicted. Zero means no limit.
+	MaxEntries int
+
+	// OnEvicted optionally specifies a callback function to be
+	// executed when an entry is purged from the cache.
+	OnEvicted func(key K, value V)
+
+	ll    *list.List
+	cache map[K]*list.Element
+}
+
+type typedEntry[K comparable, V any] struct {
+	key   K
+	value V
+}
+
+// NewTyped creates a new TypedCache.
+// If maxEntries is zero, the cache has no limit and it's assumed
+// that eviction is done by the caller.
+func NewTyped[K comparable, V any](maxEntries int) *TypedCache[K, V] {
+	return &TypedCache[K, V]{
+		MaxEntries: maxEntries,
+		ll:         list.New(),
+		cache:      make(map[K]*list.Element),
+	}
+}
+
+// Add adds a value to the cache.
+func (c *TypedCache[K, V]) Add(key K, value V) {
+	if c.cache == nil {
+		c.cache = make(map[K]*list.Element)
+		c.ll = list.New()
+	}
+	if ee, ok := c.cache[key]; ok {
+		c.ll.MoveToFront(ee)
+		ee.Value.(*typedEntry[K, V]).value = value
+		return
+	}
+	if c.MaxEntries > 0 && c.ll.Len() >= c.MaxEntries {
+		// Recycle the oldest element for the new key.
+		ele := c.ll.Back()
+		kv := ele.Value.(*typedEntry[K, V])
+		oldKey, oldValue := kv.key, kv.value
+		delete(c.cache, oldKey)
+		kv.key, kv.value = key, value
+		c.ll.MoveToFront(ele)
+		c.cache[key] = ele
+		if c.OnEvicted != nil {
+			c.OnEvicted(oldKey, oldValue)
+		}
+		return
+	}
+	ele := c.ll.PushFront(&typedEntry[K, V]{key, value})
+	c.cache[key] = ele
+	if c.MaxEntries != 0 && c.ll.Len() > c.MaxEntries {
+		c.RemoveOldest()
+	}
+}
+
+// Get looks up a key's value from the cache.
+func (c *TypedCache[K, V]) Get(key K) (value V, ok bool) {
+	if c.cache == nil {
+		return
+	}
+	if ele, hit := c.cache[key]; hit {
+		c.ll.MoveToFront(ele)
+		return ele.Value.(*typedEntry[K, V]).value, true
+	}
+	return
+}
+
+// GetOrAdd looks up a key's value from the cache, or calls loader and
+// adds its result if the key is missing. loaded reports whether the value
+// was already cached.
+func (c *TypedCache[K, V]) GetOrAdd(key K, loader func() V) (value V, loaded bool) {
+	if value, ok := c.Get(key); ok {
+		return value, true
+	}
+	value = loader()
+	c.Add(key, value)
+	return value, false
+}
+
+// Remove removes the provided key from the cache.
+func (c *TypedCache[K, V]) Remove(key K) {
+	if c.cache == nil {
+		return
+	}
+	if ele, hit := c.cache[key]; hit {
+		c.removeElement(ele)
+	}
+}
+
+// RemoveOldest removes the oldest item from the cache.
+func (c *TypedCache[K, V]) RemoveOldest() (key K, ok bool) {
+	if c.cache == nil {
+		return
+	}
+	ele := c.ll.Back()
+	if ele == nil {
+		return
+	}
+	return c.removeElement(ele), true
+}
+
+func (c *TypedCache[K, V]) removeElement(e *list.Element) K {
+	c.ll.Remove(e)
+	kv := e.Value.(*typedEntry[K, V])
+	delete(c.cache, kv.key)
+	if c.OnEvicted != nil {
+		c.OnEvicted(kv.key, kv.value)
+	}
+	return kv.key
+}
+
+// Len returns the number of items in the cache.
+func (c *TypedCache[K, V]) Len() int {
+	if c.cache == nil {
+		return 0
+	}
+	return c.ll.Len()
+}
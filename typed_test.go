@@ -0,0 +1,81 @@
+// Copyright 2016 zxfonline@sina.com. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package lru
+
+import (
+	"strconv"
+	"testing"
+)
+
+const benchEntries = 1024
+
+var benchKeys = func() []string {
+	keys := make([]string, 2*benchEntries)
+	for i := range keys {
+		keys[i] = strconv.Itoa(i)
+	}
+	return keys
+}()
+
+func BenchmarkTypedCacheAdd(b *testing.B) {
+	c := NewTyped[string, int](benchEntries)
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		c.Add(benchKeys[i%len(benchKeys)], i)
+	}
+}
+
+func BenchmarkCacheAdd(b *testing.B) {
+	c := New(benchEntries)
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		c.Add(benchKeys[i%len(benchKeys)], i)
+	}
+}
+
+func BenchmarkTypedCacheGet(b *testing.B) {
+	c := NewTyped[string, int](benchEntries)
+	for i := 0; i < benchEntries; i++ {
+		c.Add(benchKeys[i], i)
+	}
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		c.Get(benchKeys[i%benchEntries])
+	}
+}
+
+func BenchmarkCacheGet(b *testing.B) {
+	c := New(benchEntries)
+	for i := 0; i < benchEntries; i++ {
+		c.Add(benchKeys[i], i)
+	}
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		c.Get(benchKeys[i%benchEntries])
+	}
+}
+
+func TestTypedCacheRecyclesEvicted(t *testing.T) {
+	var evicted []string
+	c := NewTyped[string, int](2)
+	c.OnEvicted = func(key string, value int) { evicted = append(evicted, key) }
+	c.Add("a", 1)
+	c.Add("b", 2)
+	c.Add("c", 3)
+	if len(evicted) != 1 || evicted[0] != "a" {
+		t.Fatalf("evicted %v, want [a]", evicted)
+	}
+	if _, ok := c.Get("a"); ok {
+		t.Fatal("a still cached")
+	}
+	if v, ok := c.Get("c"); !ok || v != 3 {
+		t.Fatalf("Get(c) = %v, %v", v, ok)
+	}
+	if c.Len() != 2 {
+		t.Fatalf("Len() = %d, want 2", c.Len())
+	}
+}
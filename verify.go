@@ -0,0 +1,67 @@
+// Copyright 2016 zxfonline@sina.com. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package lru
+
+import (
+	"container/list"
+	"fmt"
+)
+
+// Verify checks that the list and the map of the cache agree and returns
+// an error describing the first inconsistency found, or nil. Such an
+// inconsistency means the cache was modified concurrently without a lock,
+// or through its exported Ll and Cache fields. It walks the whole cache
+// and is O(n), so it is meant for tests and debugging.
+func (c *Cache) Verify() error {
+	if c.Cache == nil {
+		if c.Ll != nil && c.Ll.Len() != 0 {
+			return fmt.Errorf("lru: nil map but %d list elements", c.Ll.Len())
+		}
+		return nil
+	}
+	if c.Ll == nil {
+		return fmt.Errorf("lru: nil list but %d map entries", len(c.Cache))
+	}
+	if len(c.Cache) != c.Ll.Len() {
+		return fmt.Errorf("lru: %d map entries but %d list elements", len(c.Cache), c.Ll.Len())
+	}
+	inList := make(map[*list.Element]bool, c.Ll.Len())
+	var bytes int64
+	prioritized := 0
+	for ele := c.Ll.Front(); ele != nil; ele = ele.Next() {
+		kv, ok := ele.Value.(*entry)
+		if !ok {
+			return fmt.Errorf("lru: list element holds a %T", ele.Value)
+		}
+		if c.Cache[kv.key] != ele {
+			return fmt.Errorf("lru: key %v of a list element is not mapped to it", kv.key)
+		}
+		inList[ele] = true
+		bytes += kv.cost
+		if kv.priority != 0 {
+			prioritized++
+		}
+	}
+	for key, ele := range c.Cache {
+		if !inList[ele] {
+			return fmt.Errorf("lru: key %v is mapped to an element not in the list", key)
+		}
+	}
+	if bytes != c.bytes {
+		return fmt.Errorf("lru: entries cost %d but the total cost is %d", bytes, c.bytes)
+	}
+	if prioritized != c.prioritized {
+		return fmt.Errorf("lru: %d entries have a priority but %d are counted", prioritized, c.prioritized)
+	}
+	return nil
+}
+
+// Verify checks that the list and the map of the cache agree,
+// see Cache.Verify.
+func (c *SyncCache) Verify() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.c.Verify()
+}
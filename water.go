@@ -0,0 +1,26 @@
+// Copyright 2016 zxfonline@sina.com. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package lru
+
+import "math"
+
+// checkWater calls OnHighWater or OnLowWater if the length of the cache
+// crossed HighWaterMark since the last call.
+func (c *Cache) checkWater() {
+	if c.HighWaterMark <= 0 || c.MaxEntries <= 0 {
+		return
+	}
+	size := c.Len()
+	above := size >= int(math.Ceil(c.HighWaterMark*float64(c.MaxEntries)))
+	if above == c.highWater {
+		return
+	}
+	c.highWater = above
+	if above && c.OnHighWater != nil {
+		c.callback(func() { c.OnHighWater(size, c.MaxEntries) })
+	} else if !above && c.OnLowWater != nil {
+		c.callback(func() { c.OnLowWater(size, c.MaxEntries) })
+	}
+}
@@ -0,0 +1,65 @@
+// Copyright 2016 zxfonline@sina.com. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package lru
+
+// AddDirty is like Add but marks the value as dirty: it is handed to the
+// writer of the next Flush, and to OnDirtyEvicted if it leaves the cache
+// before. Adding the key again with Add clears the mark, while Update,
+// Merge and SyncCache.CompareAndSwap keep it. The admission
+// filter of a NewTinyLFU cache does not apply, so that no write is lost.
+// It reports whether the value was stored, which fails for a nil key or
+// under NoEvict.
+func (c *Cache) AddDirty(key Key, value interface{}) bool {
+	ele := c.set(key, value, c.expireAt(c.DefaultTTL), c.costOf(key, value))
+	if ele == nil {
+		return false
+	}
+	ele.Value.(*entry).dirty = true
+	c.shrink()
+	return true
+}
+
+// Flush calls writer with the key and value of every dirty item, added
+// with AddDirty, and clears their dirty mark if writer returns nil.
+// Otherwise the items stay dirty and the error is returned. writer is
+// not called if no item is dirty, and must not modify the cache.
+func (c *Cache) Flush(writer func(map[Key]interface{}) error) error {
+	if c.Cache == nil {
+		return nil
+	}
+	var dirty []*entry
+	batch := make(map[Key]interface{})
+	for ele := c.Ll.Back(); ele != nil; ele = ele.Prev() {
+		if kv := ele.Value.(*entry); kv.dirty {
+			dirty = append(dirty, kv)
+			batch[kv.key] = kv.value
+		}
+	}
+	if len(dirty) == 0 {
+		return nil
+	}
+	if err := writer(batch); err != nil {
+		return err
+	}
+	for _, kv := range dirty {
+		kv.dirty = false
+	}
+	return nil
+}
+
+// AddDirty is like Add but marks the value as dirty, see Cache.AddDirty.
+func (c *SyncCache) AddDirty(key Key, value interface{}) bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.c.AddDirty(key, value)
+}
+
+// Flush hands every dirty item to writer, see Cache.Flush.
+// writer runs with the cache lock held, so it must not call back into c.
+func (c *SyncCache) Flush(writer func(map[Key]interface{}) error) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.c.Flush(writer)
+}